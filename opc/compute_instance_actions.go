@@ -0,0 +1,238 @@
+package opc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/go-oracle-terraform/opc"
+)
+
+// instanceActionsClient makes the requests for instances the go-oracle-terraform compute clients don't
+// support, such as changing an instance's shape, rebooting it, or reading its console. It shares the
+// authenticated session of the compute client.
+type instanceActionsClient struct {
+	httpClient     *http.Client
+	endpoint       *url.URL
+	identityDomain string
+	user           string
+	userAgent      string
+}
+
+func (c *instanceActionsClient) getUserName() string {
+	return fmt.Sprintf("/Compute-%s/%s", c.identityDomain, c.user)
+}
+
+// Qualifies the name of an object with the user's name, unless it's already qualified or is one
+// of Oracle's public objects
+func (c *instanceActionsClient) qualify(name string) string {
+	if name == "" || strings.HasPrefix(name, "/oracle") || strings.HasPrefix(name, "/Compute-") {
+		return name
+	}
+	return fmt.Sprintf("%s/%s", c.getUserName(), name)
+}
+
+// send makes a request for a single object to the compute API, with the JSON body if it's not nil,
+// decoding the JSON response into result if it's not nil
+func (c *instanceActionsClient) send(method, path string, body, result interface{}) error {
+	req, err := c.newRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	return c.do(req, result)
+}
+
+func (c *instanceActionsClient) newRequest(method, path string, body interface{}) (*http.Request, error) {
+	sendURL := c.endpoint.ResolveReference(&url.URL{Path: path})
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, sendURL.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/oracle-compute-v3+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/oracle-compute-v3+json")
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	return req, nil
+}
+
+func (c *instanceActionsClient) do(req *http.Request, result interface{}) error {
+	log.Printf("[DEBUG] Sending %s for compute object: %s", req.Method, req.URL)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &opc.OracleError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("Error parsing the response of %s: %s", req.URL.Path, err)
+	}
+	return nil
+}
+
+// LaunchInstance submits a launch plan for the instance, returning it as soon as it's launched rather
+// than once it's running. The request is left to finish when Terraform is interrupted, so that the
+// launched instance is always recorded in the state.
+func (c *instanceActionsClient) LaunchInstance(input *compute.CreateInstanceInput) (*compute.InstanceInfo, error) {
+	instance := *input
+	instance.Name = c.qualify(input.Name)
+	instance.SSHKeys = []string{}
+	for _, key := range input.SSHKeys {
+		instance.SSHKeys = append(instance.SSHKeys, c.qualify(key))
+	}
+	instance.Storage = nil
+	for _, attachment := range input.Storage {
+		instance.Storage = append(instance.Storage, compute.StorageAttachmentInput{
+			Index:  attachment.Index,
+			Volume: c.qualify(attachment.Volume),
+		})
+	}
+	instance.Networking = make(map[string]compute.NetworkingInfo, len(input.Networking))
+	for device, info := range input.Networking {
+		instance.Networking[device] = c.qualifyNetworking(info)
+	}
+
+	req, err := c.newRequest(http.MethodPost, "/launchplan/", &compute.LaunchPlanInput{
+		Instances: []compute.CreateInstanceInput{instance},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var launched compute.LaunchPlanResponse
+	if err := c.do(uninterruptible(req), &launched); err != nil {
+		return nil, err
+	}
+	if len(launched.Instances) == 0 {
+		return nil, fmt.Errorf("No instance was launched for %s", input.Name)
+	}
+	return &launched.Instances[0], nil
+}
+
+// Qualifies the names of the objects an interface refers to, as the launch plan expects
+func (c *instanceActionsClient) qualifyNetworking(info compute.NetworkingInfo) compute.NetworkingInfo {
+	ipNetwork := info.IPNetwork != ""
+	info.IPNetwork = c.qualify(info.IPNetwork)
+	info.Vnic = c.qualify(info.Vnic)
+	if info.Nat != nil {
+		nat := []string{}
+		for _, v := range info.Nat {
+			if strings.HasPrefix(v, "ippool:/oracle") {
+				nat = append(nat, v)
+				continue
+			}
+			prefix := compute.ReservationPrefix
+			if ipNetwork {
+				prefix = compute.ReservationIPPrefix
+			}
+			nat = append(nat, fmt.Sprintf("%s:%s", prefix, c.qualify(v)))
+		}
+		info.Nat = nat
+	}
+	if info.VnicSets != nil {
+		info.VnicSets = c.qualifyList(info.VnicSets)
+	}
+	if info.SecLists != nil {
+		info.SecLists = c.qualifyList(info.SecLists)
+	}
+	return info
+}
+
+func (c *instanceActionsClient) qualifyList(names []string) []string {
+	qualified := make([]string, 0, len(names))
+	for _, name := range names {
+		qualified = append(qualified, c.qualify(name))
+	}
+	return qualified
+}
+
+// UpdateInstanceShape changes the shape of an instance, which must be shut down
+func (c *instanceActionsClient) UpdateInstanceShape(name, id, shape string) error {
+	qualifiedName := fmt.Sprintf("%s/%s/%s", c.getUserName(), name, id)
+	return c.send(http.MethodPut, "/instance"+qualifiedName, map[string]string{
+		"name":  qualifiedName,
+		"shape": shape,
+	}, nil)
+}
+
+// The final states of a reboot request, as reported by the API
+const (
+	rebootRequestComplete = "complete"
+	rebootRequestError    = "error"
+)
+
+// instanceRebootRequest is a request to reboot an instance, which is processed asynchronously
+type instanceRebootRequest struct {
+	Name         string `json:"name"`
+	Instance     string `json:"instance"`
+	InstanceID   string `json:"instance_id"`
+	Hard         bool   `json:"hard"`
+	RequestState string `json:"request_state"`
+	ErrorReason  string `json:"error_reason"`
+	CreationTime string `json:"creation_time"`
+	URI          string `json:"uri"`
+}
+
+// RebootInstance requests the instance to be rebooted. A hard reboot resets the instance, rather
+// than shutting its operating system down first.
+func (c *instanceActionsClient) RebootInstance(name, id string, hard bool) (*instanceRebootRequest, error) {
+	var result instanceRebootRequest
+	err := c.send(http.MethodPost, "/rebootinstancerequest/", map[string]interface{}{
+		"instance": fmt.Sprintf("%s/%s/%s", c.getUserName(), name, id),
+		"hard":     hard,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetRebootInstanceRequest fetches the reboot request with the fully qualified name returned by RebootInstance
+func (c *instanceActionsClient) GetRebootInstanceRequest(name string) (*instanceRebootRequest, error) {
+	var result instanceRebootRequest
+	if err := c.send(http.MethodGet, "/rebootinstancerequest"+name, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// instanceConsole holds the output of an instance's serial console
+type instanceConsole struct {
+	Name      string `json:"name"`
+	Output    string `json:"output"`
+	Timestamp string `json:"timestamp"`
+}
+
+// GetInstanceConsole fetches the recent output of the instance's serial console
+func (c *instanceActionsClient) GetInstanceConsole(name, id string) (*instanceConsole, error) {
+	var result instanceConsole
+	if err := c.send(http.MethodGet, fmt.Sprintf("/instanceconsole%s/%s/%s", c.getUserName(), name, id), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,124 @@
+package opc
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/terraform-providers/terraform-provider-opc/opc/simulator"
+)
+
+// Records the Accept header of every request sent through the transport
+type acceptRecordingTransport struct {
+	mu        sync.Mutex
+	accepts   []string
+	transport http.RoundTripper
+}
+
+func (t *acceptRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.accepts = append(t.accepts, req.Header.Get("Accept"))
+	t.mu.Unlock()
+	return t.transport.RoundTrip(req)
+}
+
+func TestInstanceActionsClient(t *testing.T) {
+	s := simulator.New()
+	defer s.Close()
+
+	client, err := testSessionConfig(s).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err := client.getInstanceActionsClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &acceptRecordingTransport{transport: actions.httpClient.Transport}
+	actions.httpClient = newHTTPClient(recorder)
+
+	var launched struct {
+		Instances []compute.InstanceInfo `json:"instances"`
+	}
+	if err := actions.send(http.MethodPost, "/launchplan/", map[string]interface{}{
+		"instances": []interface{}{
+			map[string]interface{}{
+				"name":      actions.getUserName() + "/reboot-test",
+				"shape":     "oc3",
+				"imagelist": "/oracle/public/OL_7.2_UEKR4_x86_64",
+			},
+		},
+	}, &launched); err != nil {
+		t.Fatalf("Error launching instance: %s", err)
+	}
+	id := launched.Instances[0].ID
+
+	request, err := actions.RebootInstance("reboot-test", id, true)
+	if err != nil {
+		t.Fatalf("Error rebooting instance: %s", err)
+	}
+	if !request.Hard || request.InstanceID != id {
+		t.Fatalf("Expected a hard reboot request for instance %s, got %+v", id, request)
+	}
+	request, err = actions.GetRebootInstanceRequest(request.Name)
+	if err != nil {
+		t.Fatalf("Error reading the reboot request: %s", err)
+	}
+	if request.RequestState != rebootRequestComplete {
+		t.Fatalf("Expected the reboot request to be complete, got %q", request.RequestState)
+	}
+
+	console, err := actions.GetInstanceConsole("reboot-test", id)
+	if err != nil {
+		t.Fatalf("Error reading the instance console: %s", err)
+	}
+	if !strings.Contains(console.Output, "Rebooting instance") {
+		t.Fatalf("Expected the console output to show the reboot, got %q", console.Output)
+	}
+
+	if _, err := actions.RebootInstance("reboot-test", "missing", false); err == nil {
+		t.Fatal("Expected an error rebooting a missing instance")
+	}
+
+	// Single objects are read as such, rather than as directory listings
+	for _, accept := range recorder.accepts {
+		if accept != "application/oracle-compute-v3+json" {
+			t.Fatalf("Expected every request to accept application/oracle-compute-v3+json, got %v", recorder.accepts)
+		}
+	}
+}
+
+func TestInstanceActionsClient_qualifyNetworking(t *testing.T) {
+	actions := &instanceActionsClient{identityDomain: "mydomain", user: "user@example.com"}
+	user := "/Compute-mydomain/user@example.com"
+
+	shared := actions.qualifyNetworking(compute.NetworkingInfo{
+		Nat:      []string{"ippool:/oracle/public/ippool", "reserved"},
+		SecLists: []string{"default", "/Compute-mydomain/other@example.com/list"},
+	})
+	if expected := []string{"ippool:/oracle/public/ippool", "ipreservation:" + user + "/reserved"}; !reflect.DeepEqual(shared.Nat, expected) {
+		t.Errorf("Expected shared network NAT %v, got %v", expected, shared.Nat)
+	}
+	if expected := []string{user + "/default", "/Compute-mydomain/other@example.com/list"}; !reflect.DeepEqual(shared.SecLists, expected) {
+		t.Errorf("Expected security lists %v, got %v", expected, shared.SecLists)
+	}
+
+	ipNetwork := actions.qualifyNetworking(compute.NetworkingInfo{
+		IPNetwork: "network",
+		Nat:       []string{"reserved"},
+		Vnic:      "vnic",
+		VnicSets:  []string{"vnicset"},
+	})
+	if ipNetwork.IPNetwork != user+"/network" || ipNetwork.Vnic != user+"/vnic" {
+		t.Errorf("Expected the IP network and vNIC to be qualified, got %s and %s", ipNetwork.IPNetwork, ipNetwork.Vnic)
+	}
+	if expected := []string{"network/v1/ipreservation:" + user + "/reserved"}; !reflect.DeepEqual(ipNetwork.Nat, expected) {
+		t.Errorf("Expected IP network NAT %v, got %v", expected, ipNetwork.Nat)
+	}
+	if expected := []string{user + "/vnicset"}; !reflect.DeepEqual(ipNetwork.VnicSets, expected) {
+		t.Errorf("Expected vNIC sets %v, got %v", expected, ipNetwork.VnicSets)
+	}
+}
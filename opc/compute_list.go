@@ -0,0 +1,573 @@
+package opc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/go-oracle-terraform/opc"
+)
+
+// computeListClient lists the objects in a Compute Classic container, e.g. /instance/Compute-domain/user/.
+// The go-oracle-terraform compute clients can only operate on a single, named object, so this client
+// makes the requests itself, sharing the authenticated session of the compute client.
+type computeListClient struct {
+	httpClient     *http.Client
+	endpoint       *url.URL
+	identityDomain string
+	user           string
+	userAgent      string
+
+	shapesLock   sync.Mutex
+	shapes       []computeShape
+	shapesListed bool
+}
+
+// computeListInput filters the objects returned by a list operation
+type computeListInput struct {
+	// The container to list, e.g. /oracle/public. Defaults to the user's container, /Compute-domain/user
+	Container string
+	// Only return objects with all of these tags. Passed to the API as the `tags` query filter.
+	Tags []string
+	// Only return objects whose unqualified name starts with this prefix
+	NamePrefix string
+}
+
+type computeListResult struct {
+	Result []json.RawMessage `json:"result"`
+}
+
+// The fields of every compute object used for filtering
+type computeListObject struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func (c *computeListClient) getUserName() string {
+	return fmt.Sprintf("/Compute-%s/%s", c.identityDomain, c.user)
+}
+
+// Returns the {name} part of /Compute-domain/user/{name}, if the object belongs to the current user.
+// Matches the names returned by the go-oracle-terraform compute clients.
+func (c *computeListClient) getUnqualifiedName(name string) string {
+	if name == "" || strings.HasPrefix(name, "/oracle") || !strings.Contains(name, "/") {
+		return name
+	}
+	nameParts := strings.Split(name, "/")
+	if len(nameParts) < 4 || fmt.Sprintf("/%s/%s", nameParts[1], nameParts[2]) != c.getUserName() {
+		return name
+	}
+	return strings.Join(nameParts[3:], "/")
+}
+
+// list fetches the objects under the resource root, e.g. /seclist, applying the input's filters,
+// and decodes them into result, which must be a pointer to a slice
+func (c *computeListClient) list(root string, input *computeListInput, result interface{}) error {
+	if input == nil {
+		input = &computeListInput{}
+	}
+	container := input.Container
+	if container == "" {
+		container = c.getUserName()
+	}
+	path := fmt.Sprintf("%s/%s/", strings.TrimSuffix(root, "/"), strings.Trim(container, "/"))
+
+	query := url.Values{}
+	for _, tag := range input.Tags {
+		query.Add("tags", tag)
+	}
+
+	var listResult computeListResult
+	if err := c.get(path, query, &listResult); err != nil {
+		return err
+	}
+
+	filtered := make([]json.RawMessage, 0, len(listResult.Result))
+	for _, raw := range listResult.Result {
+		var object computeListObject
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return fmt.Errorf("Error parsing the list of %s: %s", path, err)
+		}
+		if input.NamePrefix != "" && !strings.HasPrefix(c.getUnqualifiedName(object.Name), input.NamePrefix) {
+			continue
+		}
+		// The API applies the tags filter, but not every object type supports it
+		if !hasAllTags(object.Tags, input.Tags) {
+			continue
+		}
+		filtered = append(filtered, raw)
+	}
+
+	b, err := json.Marshal(filtered)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, result)
+}
+
+// get makes a GET request for a directory listing to the compute API, decoding the JSON response into result
+func (c *computeListClient) get(path string, query url.Values, result interface{}) error {
+	getURL := c.endpoint.ResolveReference(&url.URL{Path: path, RawQuery: query.Encode()})
+
+	req, err := http.NewRequest(http.MethodGet, getURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/oracle-compute-v3+directory+json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	log.Printf("[DEBUG] Getting compute objects: %s", getURL)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &opc.OracleError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("Error parsing the response of %s: %s", path, err)
+	}
+	return nil
+}
+
+func hasAllTags(tags, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, t := range tags {
+			if t == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ListInstances lists instances. Unlike GetInstance, the networking and storage references are
+// left fully qualified.
+func (c *computeListClient) ListInstances(input *computeListInput) ([]compute.InstanceInfo, error) {
+	var result []compute.InstanceInfo
+	if err := c.list("/instance", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		// The returned name is the fully qualified instance name + "/" + ID
+		nID := strings.Split(c.getUnqualifiedName(result[i].FQDN), "/")
+		result[i].Name = strings.Join(nID[0:len(nID)-1], "/")
+		result[i].ID = nID[len(nID)-1]
+	}
+	return result, nil
+}
+
+// ListStorageVolumes lists storage volumes. As with GetStorageVolume, sizes are converted from the bytes
+// reported by the API to GB, and the image list and snapshot are unqualified.
+func (c *computeListClient) ListStorageVolumes(input *computeListInput) ([]compute.StorageVolumeInfo, error) {
+	var result []compute.StorageVolumeInfo
+	if err := c.list("/storage/volume", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+		result[i].ImageList = c.getUnqualifiedName(result[i].ImageList)
+		result[i].Snapshot = c.getUnqualifiedName(result[i].Snapshot)
+		size, err := strconv.ParseInt(result[i].Size, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing the size of storage volume %s: %s", result[i].Name, err)
+		}
+		result[i].Size = strconv.FormatInt(size/(1024*1024*1024), 10)
+	}
+	return result, nil
+}
+
+// ListStorageAttachments lists storage attachments
+func (c *computeListClient) ListStorageAttachments(input *computeListInput) ([]compute.StorageAttachmentInfo, error) {
+	var result []compute.StorageAttachmentInfo
+	if err := c.list("/storage/attachment", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListStorageVolumeSnapshots lists storage volume snapshots
+func (c *computeListClient) ListStorageVolumeSnapshots(input *computeListInput) ([]compute.StorageVolumeSnapshotInfo, error) {
+	var result []compute.StorageVolumeSnapshotInfo
+	if err := c.list("/storage/snapshot", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListImageLists lists image lists
+func (c *computeListClient) ListImageLists(input *computeListInput) ([]compute.ImageList, error) {
+	var result []compute.ImageList
+	if err := c.list("/imagelist", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListMachineImages lists machine images
+func (c *computeListClient) ListMachineImages(input *computeListInput) ([]compute.MachineImage, error) {
+	var result []compute.MachineImage
+	if err := c.list("/machineimage", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSnapshots lists instance snapshots
+func (c *computeListClient) ListSnapshots(input *computeListInput) ([]compute.Snapshot, error) {
+	var result []compute.Snapshot
+	if err := c.list("/snapshot", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListOrchestrations lists orchestrations
+func (c *computeListClient) ListOrchestrations(input *computeListInput) ([]compute.Orchestration, error) {
+	var result []compute.Orchestration
+	if err := c.list("/platform/v1/orchestration", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSSHKeys lists SSH keys
+func (c *computeListClient) ListSSHKeys(input *computeListInput) ([]compute.SSHKey, error) {
+	var result []compute.SSHKey
+	if err := c.list("/sshkey", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListIPReservations lists shared network IP reservations
+func (c *computeListClient) ListIPReservations(input *computeListInput) ([]compute.IPReservation, error) {
+	var result []compute.IPReservation
+	if err := c.list("/ip/reservation", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSecurityLists lists shared network security lists
+func (c *computeListClient) ListSecurityLists(input *computeListInput) ([]compute.SecurityListInfo, error) {
+	var result []compute.SecurityListInfo
+	if err := c.list("/seclist", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSecurityIPLists lists shared network security IP lists
+func (c *computeListClient) ListSecurityIPLists(input *computeListInput) ([]compute.SecurityIPListInfo, error) {
+	var result []compute.SecurityIPListInfo
+	if err := c.list("/seciplist", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSecurityApplications lists shared network security applications
+func (c *computeListClient) ListSecurityApplications(input *computeListInput) ([]compute.SecurityApplicationInfo, error) {
+	var result []compute.SecurityApplicationInfo
+	if err := c.list("/secapplication", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSecRules lists shared network security rules
+func (c *computeListClient) ListSecRules(input *computeListInput) ([]compute.SecRuleInfo, error) {
+	var result []compute.SecRuleInfo
+	if err := c.list("/secrule", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListIPNetworks lists IP networks
+func (c *computeListClient) ListIPNetworks(input *computeListInput) ([]compute.IPNetworkInfo, error) {
+	var result []compute.IPNetworkInfo
+	if err := c.list("/network/v1/ipnetwork", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListIPAddressReservations lists IP network IP address reservations
+func (c *computeListClient) ListIPAddressReservations(input *computeListInput) ([]compute.IPAddressReservation, error) {
+	var result []compute.IPAddressReservation
+	if err := c.list("/network/v1/ipreservation", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListVirtualNICSets lists virtual NIC sets
+func (c *computeListClient) ListVirtualNICSets(input *computeListInput) ([]compute.VirtualNICSet, error) {
+	var result []compute.VirtualNICSet
+	if err := c.list("/network/v1/vnicset", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListACLs lists IP network access control lists
+func (c *computeListClient) ListACLs(input *computeListInput) ([]compute.ACLInfo, error) {
+	var result []compute.ACLInfo
+	if err := c.list("/network/v1/acl", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListRoutes lists IP network routes
+func (c *computeListClient) ListRoutes(input *computeListInput) ([]compute.RouteInfo, error) {
+	var result []compute.RouteInfo
+	if err := c.list("/network/v1/route", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListImageListEntries lists the entries of the image lists matching the input. The Name of each entry
+// is the name of its image list.
+func (c *computeListClient) ListImageListEntries(input *computeListInput) ([]compute.ImageListEntryInfo, error) {
+	imageLists, err := c.ListImageLists(input)
+	if err != nil {
+		return nil, err
+	}
+	result := []compute.ImageListEntryInfo{}
+	for _, imageList := range imageLists {
+		for _, entry := range imageList.Entries {
+			result = append(result, compute.ImageListEntryInfo{
+				Attributes:    entry.Attributes,
+				Name:          imageList.Name,
+				MachineImages: entry.MachineImages,
+				URI:           entry.URI,
+				Version:       entry.Version,
+			})
+		}
+	}
+	return result, nil
+}
+
+// ListIPAssociations lists shared network IP associations
+func (c *computeListClient) ListIPAssociations(input *computeListInput) ([]compute.IPAssociationInfo, error) {
+	var result []compute.IPAssociationInfo
+	if err := c.list("/ip/association", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSecurityAssociations lists shared network security associations
+func (c *computeListClient) ListSecurityAssociations(input *computeListInput) ([]compute.SecurityAssociationInfo, error) {
+	var result []compute.SecurityAssociationInfo
+	if err := c.list("/secassociation", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListIPAddressAssociations lists IP network IP address associations
+func (c *computeListClient) ListIPAddressAssociations(input *computeListInput) ([]compute.IPAddressAssociationInfo, error) {
+	var result []compute.IPAddressAssociationInfo
+	if err := c.list("/network/v1/ipassociation", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListIPAddressPrefixSets lists IP network IP address prefix sets
+func (c *computeListClient) ListIPAddressPrefixSets(input *computeListInput) ([]compute.IPAddressPrefixSetInfo, error) {
+	var result []compute.IPAddressPrefixSetInfo
+	if err := c.list("/network/v1/ipaddressprefixset", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListIPNetworkExchanges lists IP network exchanges
+func (c *computeListClient) ListIPNetworkExchanges(input *computeListInput) ([]compute.IPNetworkExchangeInfo, error) {
+	var result []compute.IPNetworkExchangeInfo
+	if err := c.list("/network/v1/ipnetworkexchange", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSecurityProtocols lists IP network security protocols
+func (c *computeListClient) ListSecurityProtocols(input *computeListInput) ([]compute.SecurityProtocolInfo, error) {
+	var result []compute.SecurityProtocolInfo
+	if err := c.list("/network/v1/secprotocol", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListSecurityRules lists IP network security rules
+func (c *computeListClient) ListSecurityRules(input *computeListInput) ([]compute.SecurityRuleInfo, error) {
+	var result []compute.SecurityRuleInfo
+	if err := c.list("/network/v1/secrule", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListVirtualNICs lists the virtual NICs of instances on IP networks
+func (c *computeListClient) ListVirtualNICs(input *computeListInput) ([]compute.VirtualNIC, error) {
+	var result []compute.VirtualNIC
+	if err := c.list("/network/v1/vnic", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].FQDN)
+	}
+	return result, nil
+}
+
+// ListVPNEndpointV2s lists VPN endpoints. Their names are returned unqualified, as VPN endpoints
+// have no separate FQDN field.
+func (c *computeListClient) ListVPNEndpointV2s(input *computeListInput) ([]compute.VPNEndpointV2Info, error) {
+	var result []compute.VPNEndpointV2Info
+	if err := c.list("/vpnendpoint/v2", input, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Name = c.getUnqualifiedName(result[i].Name)
+	}
+	return result, nil
+}
+
+// computeShape describes the resources of an instance shape
+type computeShape struct {
+	Name                  string   `json:"name"`
+	CPUs                  float64  `json:"cpus"`
+	RAM                   int      `json:"ram"`
+	GPUs                  int      `json:"gpus"`
+	IO                    int      `json:"io"`
+	RootDiskSize          int      `json:"root_disk_size"`
+	IsRootSSD             bool     `json:"is_root_ssd"`
+	SSDDataSize           int      `json:"ssd_data_size"`
+	NDSIOPSLimit          int      `json:"nds_iops_limit"`
+	PlacementRequirements []string `json:"placement_requirements"`
+	URI                   string   `json:"uri"`
+}
+
+// ListShapes lists the shapes available to the account. Shapes aren't held in a container,
+// so there are no filters.
+func (c *computeListClient) ListShapes() ([]computeShape, error) {
+	var result struct {
+		Result []computeShape `json:"result"`
+	}
+	if err := c.get("/shape/", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// Returns the shapes available to the account, only listing them once per provider run.
+// A failed list isn't cached, so the next call lists them again.
+func (c *computeListClient) getCachedShapes() ([]computeShape, error) {
+	c.shapesLock.Lock()
+	defer c.shapesLock.Unlock()
+	if !c.shapesListed {
+		shapes, err := c.ListShapes()
+		if err != nil {
+			return nil, err
+		}
+		c.shapes, c.shapesListed = shapes, true
+	}
+	return c.shapes, nil
+}
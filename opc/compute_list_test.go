@@ -0,0 +1,143 @@
+package opc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/terraform-providers/terraform-provider-opc/opc/simulator"
+)
+
+func TestComputeListClient(t *testing.T) {
+	s := simulator.New()
+	defer s.Close()
+
+	client, err := testSessionConfig(s).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listClient, err := client.getComputeListClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	networks := client.computeClient.IPNetworks()
+	for _, network := range []struct {
+		Name   string
+		Prefix string
+		Tags   []string
+	}{
+		{"web-1", "10.0.1.0/24", []string{"role=web", "env=prod"}},
+		{"web-2", "10.0.2.0/24", []string{"role=web", "env=test"}},
+		{"db-1", "10.0.3.0/24", []string{"role=db", "env=prod"}},
+	} {
+		if _, err := networks.CreateIPNetwork(&compute.CreateIPNetworkInput{
+			Name:              network.Name,
+			IPAddressPrefix:   network.Prefix,
+			Tags:              network.Tags,
+			PublicNaptEnabled: false,
+		}); err != nil {
+			t.Fatalf("Error creating IP network: %s", err)
+		}
+	}
+
+	cases := []struct {
+		Input    *computeListInput
+		Expected []string
+	}{
+		{nil, []string{"db-1", "web-1", "web-2"}},
+		{&computeListInput{NamePrefix: "web-"}, []string{"web-1", "web-2"}},
+		{&computeListInput{Tags: []string{"env=prod"}}, []string{"db-1", "web-1"}},
+		{&computeListInput{Tags: []string{"role=web", "env=prod"}}, []string{"web-1"}},
+		{&computeListInput{NamePrefix: "db-", Tags: []string{"role=web"}}, []string{}},
+	}
+
+	for _, tc := range cases {
+		result, err := listClient.ListIPNetworks(tc.Input)
+		if err != nil {
+			t.Fatalf("Error listing IP networks: %s", err)
+		}
+		names := make([]string, 0, len(result))
+		for _, network := range result {
+			names = append(names, network.Name)
+		}
+		if len(names) != len(tc.Expected) {
+			t.Fatalf("Expected %v for %+v, got %v", tc.Expected, tc.Input, names)
+		}
+		for i := range names {
+			if names[i] != tc.Expected[i] {
+				t.Fatalf("Expected %v for %+v, got %v", tc.Expected, tc.Input, names)
+			}
+		}
+	}
+
+	// Oracle provided image lists are listed from the public container, and keep their qualified names
+	imageLists, err := listClient.ListImageLists(&computeListInput{Container: "/oracle/public", NamePrefix: "/oracle/public/OL_7"})
+	if err != nil {
+		t.Fatalf("Error listing image lists: %s", err)
+	}
+	if len(imageLists) != 1 || imageLists[0].Name != "/oracle/public/OL_7.2_UEKR4_x86_64" {
+		t.Fatalf("Expected the public OL_7.2_UEKR4_x86_64 image list, got %+v", imageLists)
+	}
+	entries, err := listClient.ListImageListEntries(&computeListInput{Container: "/oracle/public", NamePrefix: "/oracle/public/OL_7"})
+	if err != nil {
+		t.Fatalf("Error listing image list entries: %s", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "/oracle/public/OL_7.2_UEKR4_x86_64" || entries[0].Version != 1 {
+		t.Fatalf("Expected the first entry of the public OL_7.2_UEKR4_x86_64 image list, got %+v", entries)
+	}
+
+	// Sizes are in GB, as returned by GetStorageVolume
+	if _, err := client.computeClient.StorageVolumes().CreateStorageVolume(&compute.CreateStorageVolumeInput{
+		Name:         "data",
+		Size:         "10",
+		Properties:   []string{"/oracle/public/storage/default"},
+		PollInterval: time.Millisecond,
+		Timeout:      time.Minute,
+	}); err != nil {
+		t.Fatalf("Error creating storage volume: %s", err)
+	}
+	volumes, err := listClient.ListStorageVolumes(nil)
+	if err != nil {
+		t.Fatalf("Error listing storage volumes: %s", err)
+	}
+	if len(volumes) != 1 || volumes[0].Name != "data" || volumes[0].Size != "10" {
+		t.Fatalf("Expected the 10GB data volume, got %+v", volumes)
+	}
+}
+
+func TestComputeListClient_getCachedShapes(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"result": [{"name": "oc3", "cpus": 1, "ram": 7680}]}`))
+	}))
+	defer server.Close()
+
+	endpoint, _ := url.Parse(server.URL)
+	listClient := &computeListClient{httpClient: server.Client(), endpoint: endpoint}
+
+	// The failed list isn't cached
+	if _, err := listClient.getCachedShapes(); err == nil {
+		t.Fatal("Expected an error listing the shapes")
+	}
+	for i := 0; i < 2; i++ {
+		shapes, err := listClient.getCachedShapes()
+		if err != nil {
+			t.Fatalf("Error listing the shapes: %s", err)
+		}
+		if len(shapes) != 1 || shapes[0].Name != "oc3" {
+			t.Fatalf("Expected the oc3 shape, got %#v", shapes)
+		}
+	}
+	if requests != 2 {
+		t.Fatalf("Expected the shapes to be listed twice, got %d requests", requests)
+	}
+}
@@ -1,11 +1,13 @@
 package opc
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-oracle-terraform/compute"
@@ -27,13 +29,49 @@ type Config struct {
 	StorageEndpoint  string
 	StorageServiceID string
 	LBaaSEndpoint    string
+
+	RetryableStatusCodes []int
+	RetryMaxElapsedTime  time.Duration
+	RetryBaseBackoff     time.Duration
+	RetryMaxBackoff      time.Duration
+
+	// The wait between checks of a resource's state, and the maximum the provider's waits back off to
+	PollInterval    time.Duration
+	PollMaxInterval time.Duration
+
+	// Cancelled when Terraform is interrupted
+	StopContext context.Context
+
+	// TLS and proxy settings for every service, and the overrides for each service
+	Transport        transportConfig
+	ComputeTransport transportConfig
+	StorageTransport transportConfig
+	LBaaSTransport   transportConfig
+
+	// Records every request and response to this file, as HAR if it ends in .har, else JSON lines
+	HTTPTrace string
 }
 
 // Client holder for the OPC (OCI Classic) API Clients
 type Client struct {
-	computeClient *compute.Client
-	storageClient *storage.Client
-	lbaasClient   *lbaas.Client
+	computeClient     *compute.Client
+	computeListClient *computeListClient
+	instanceActions   *instanceActionsClient
+	storageClient     *storage.Client
+	storageAPIClient  *storageAPIClient
+	lbaasClient       *lbaas.Client
+
+	// Used to create resources, their requests aren't cancelled when Terraform is interrupted
+	computeCreateClient *compute.Client
+	lbaasCreateClient   *lbaas.Client
+
+	// The number of times a failed instance launch is retried
+	maxRetries int
+
+	stopContext context.Context
+	// The poll interval given to the API clients, zero for their defaults
+	pollInterval time.Duration
+	pollPolicy   pollPolicy
 }
 
 // Client gets the OPC (OCI Classic) API Clients
@@ -41,11 +79,13 @@ func (c *Config) Client() (*Client, error) {
 
 	userAgentString := fmt.Sprintf("HashiCorp-Terraform-v%s", terraform.VersionString())
 
+	// Retries are handled by the retryTransport, and failed instance launches are launched again by
+	// resourceInstanceCreate, so the API clients make a single attempt
 	config := opc.Config{
 		IdentityDomain: &c.IdentityDomain,
 		Username:       &c.User,
 		Password:       &c.Password,
-		MaxRetries:     &c.MaxRetries,
+		MaxRetries:     opc.Int(1),
 		UserAgent:      &userAgentString,
 	}
 
@@ -54,31 +94,55 @@ func (c *Config) Client() (*Client, error) {
 		config.Logger = opcLogger{}
 	}
 
-	// Setup HTTP Client based on insecure
-	httpClient := cleanhttp.DefaultClient()
-	if c.Insecure {
-		transport := cleanhttp.DefaultTransport()
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
-		httpClient.Transport = transport
+	client := &Client{
+		maxRetries:   c.MaxRetries,
+		stopContext:  c.StopContext,
+		pollInterval: c.PollInterval,
+		pollPolicy:   c.pollPolicy(),
 	}
 
-	config.HTTPClient = httpClient
-
-	client := &Client{}
-
 	if c.Endpoint != "" {
 		computeEndpoint, err := url.ParseRequestURI(c.Endpoint)
 		if err != nil {
 			return nil, fmt.Errorf("Invalid Compute Endpoint URI: %s", err)
 		}
 		config.APIEndpoint = computeEndpoint
+		retry, err := c.serviceTransport("Compute", c.ComputeTransport)
+		if err != nil {
+			return nil, fmt.Errorf("Error configuring the Compute transport: %s", err)
+		}
+		// Every compute sub-client shares the authentication cookie held by the session
+		config.HTTPClient = newHTTPClient(newSessionTransport("Compute", &computeAuthenticator{
+			endpoint:       computeEndpoint,
+			identityDomain: c.IdentityDomain,
+			user:           c.User,
+			password:       c.Password,
+			userAgent:      userAgentString,
+		}, retry))
 		computeClient, err := compute.NewComputeClient(&config)
 		if err != nil {
 			return nil, err
 		}
 		client.computeClient = computeClient
+		createConfig := config
+		createConfig.HTTPClient = newHTTPClient(&uninterruptibleTransport{transport: config.HTTPClient.Transport})
+		if client.computeCreateClient, err = compute.NewComputeClient(&createConfig); err != nil {
+			return nil, err
+		}
+		client.computeListClient = &computeListClient{
+			httpClient:     config.HTTPClient,
+			endpoint:       computeEndpoint,
+			identityDomain: c.IdentityDomain,
+			user:           c.User,
+			userAgent:      userAgentString,
+		}
+		client.instanceActions = &instanceActionsClient{
+			httpClient:     config.HTTPClient,
+			endpoint:       computeEndpoint,
+			identityDomain: c.IdentityDomain,
+			user:           c.User,
+			userAgent:      userAgentString,
+		}
 		log.Print("[DEBUG] Authenticated with Compute Client")
 
 	}
@@ -89,14 +153,32 @@ func (c *Config) Client() (*Client, error) {
 			return nil, fmt.Errorf("Invalid Storage Endpoint URI: %+v", err)
 		}
 		config.APIEndpoint = storageEndpoint
+		retry, err := c.serviceTransport("Storage", c.StorageTransport)
+		if err != nil {
+			return nil, fmt.Errorf("Error configuring the Storage transport: %s", err)
+		}
 		if (c.StorageServiceID) != "" {
 			config.IdentityDomain = &c.StorageServiceID
 		}
+		// Every storage sub-client shares the authentication token held by the session
+		config.HTTPClient = newHTTPClient(newSessionTransport("Storage", &storageAuthenticator{
+			endpoint:  storageEndpoint,
+			serviceID: *config.IdentityDomain,
+			user:      c.User,
+			password:  c.Password,
+			userAgent: userAgentString,
+		}, retry))
 		storageClient, err := storage.NewStorageClient(&config)
 		if err != nil {
 			return nil, err
 		}
 		client.storageClient = storageClient
+		client.storageAPIClient = &storageAPIClient{
+			httpClient: config.HTTPClient,
+			endpoint:   storageEndpoint,
+			account:    fmt.Sprintf("Storage-%s", *config.IdentityDomain),
+			userAgent:  userAgentString,
+		}
 		log.Print("[DEBUG] Authenticated with Storage Client")
 
 	}
@@ -107,17 +189,103 @@ func (c *Config) Client() (*Client, error) {
 			return nil, fmt.Errorf("Invalid LBaaS Endpoint URI: %+v", err)
 		}
 		config.APIEndpoint = lbaasEndpoint
+		retry, err := c.serviceTransport("LBaaS", c.LBaaSTransport)
+		if err != nil {
+			return nil, fmt.Errorf("Error configuring the LBaaS transport: %s", err)
+		}
+		// The Load Balancer API uses basic authentication on every request, so has no session
+		config.HTTPClient = newHTTPClient(retry)
 		lbaasClient, err := lbaas.NewClient(&config)
 		if err != nil {
 			return nil, err
 		}
+		lbaasClient.PollInterval = c.PollInterval
 		client.lbaasClient = lbaasClient
+		createConfig := config
+		createConfig.HTTPClient = newHTTPClient(&uninterruptibleTransport{transport: retry})
+		if client.lbaasCreateClient, err = lbaas.NewClient(&createConfig); err != nil {
+			return nil, err
+		}
+		client.lbaasCreateClient.PollInterval = c.PollInterval
 		log.Print("[DEBUG] Authenticated with Load Balancer Client")
 	}
 
 	return client, nil
 }
 
+func newHTTPClient(transport http.RoundTripper) *http.Client {
+	httpClient := cleanhttp.DefaultClient()
+	httpClient.Transport = transport
+	return httpClient
+}
+
+// Builds the transport for a service, with its TLS and proxy settings overriding the provider's
+func (c *Config) serviceTransport(service string, override transportConfig) (*retryTransport, error) {
+	var transport http.RoundTripper
+	transport, err := c.Transport.merge(override).transport(c.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	if c.HTTPTrace != "" {
+		tracer, err := openHTTPTracer(c.HTTPTrace)
+		if err != nil {
+			return nil, err
+		}
+		transport = &traceTransport{
+			tracer:    tracer,
+			service:   service,
+			transport: transport,
+		}
+	}
+	return &retryTransport{
+		policy:    c.retryPolicy(),
+		transport: transport,
+		stop:      c.StopContext,
+	}, nil
+}
+
+// Builds the retry policy from the provider configuration, applying defaults for unset values
+func (c *Config) retryPolicy() retryPolicy {
+	policy := retryPolicy{
+		MaxRetries:           c.MaxRetries,
+		RetryableStatusCodes: c.RetryableStatusCodes,
+		MaxElapsedTime:       c.RetryMaxElapsedTime,
+		BaseBackoff:          c.RetryBaseBackoff,
+		MaxBackoff:           c.RetryMaxBackoff,
+	}
+	if len(policy.RetryableStatusCodes) == 0 {
+		policy.RetryableStatusCodes = defaultRetryableStatusCodes
+	}
+	if policy.MaxElapsedTime == 0 {
+		policy.MaxElapsedTime = defaultRetryMaxElapsedTime
+	}
+	if policy.BaseBackoff == 0 {
+		policy.BaseBackoff = defaultRetryBaseBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = defaultRetryMaxBackoff
+	}
+	return policy
+}
+
+// Builds the poll policy for the provider's own waits, applying defaults for unset values
+func (c *Config) pollPolicy() pollPolicy {
+	policy := pollPolicy{
+		Interval:    c.PollInterval,
+		MaxInterval: c.PollMaxInterval,
+	}
+	if policy.Interval == 0 {
+		policy.Interval = defaultPollInterval
+	}
+	if policy.MaxInterval == 0 {
+		policy.MaxInterval = defaultPollMaxInterval
+	}
+	if policy.MaxInterval < policy.Interval {
+		policy.MaxInterval = policy.Interval
+	}
+	return policy
+}
+
 type opcLogger struct{}
 
 func (l opcLogger) Log(args ...interface{}) {
@@ -138,6 +306,29 @@ func (c *Client) getComputeClient() (*compute.Client, error) {
 	return c.computeClient, nil
 }
 
+// Returns the compute client used to create resources, which finishes creating them even when
+// Terraform is interrupted
+func (c *Client) getComputeCreateClient() (*compute.Client, error) {
+	if c.computeCreateClient == nil {
+		return nil, fmt.Errorf("Compute API client has not been initialized. Ensure the `endpoint` for the Compute Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.computeCreateClient, nil
+}
+
+func (c *Client) getComputeListClient() (*computeListClient, error) {
+	if c.computeListClient == nil {
+		return nil, fmt.Errorf("Compute API client has not been initialized. Ensure the `endpoint` for the Compute Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.computeListClient, nil
+}
+
+func (c *Client) getInstanceActionsClient() (*instanceActionsClient, error) {
+	if c.instanceActions == nil {
+		return nil, fmt.Errorf("Compute API client has not been initialized. Ensure the `endpoint` for the Compute Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.instanceActions, nil
+}
+
 func (c *Client) getStorageClient() (*storage.Client, error) {
 	if c.storageClient == nil {
 		return nil, fmt.Errorf("Storage API client has not been initialized. Ensure the `storage_endpoint` for the Object Storage Classic REST API Endpoint has been declared in the provider configuration.")
@@ -145,9 +336,25 @@ func (c *Client) getStorageClient() (*storage.Client, error) {
 	return c.storageClient, nil
 }
 
+func (c *Client) getStorageAPIClient() (*storageAPIClient, error) {
+	if c.storageAPIClient == nil {
+		return nil, fmt.Errorf("Storage API client has not been initialized. Ensure the `storage_endpoint` for the Object Storage Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.storageAPIClient, nil
+}
+
 func (c *Client) getLBaaSClient() (*lbaas.Client, error) {
 	if c.lbaasClient == nil {
 		return nil, fmt.Errorf("Load Balancer API client has not been initialized. Ensure the `lbaas_endpoint` for the Load Balancer Classic REST API Endpoint has been declared in the provider configuration.")
 	}
 	return c.lbaasClient, nil
 }
+
+// Returns the Load Balancer client used to create resources, which finishes creating them even when
+// Terraform is interrupted
+func (c *Client) getLBaaSCreateClient() (*lbaas.Client, error) {
+	if c.lbaasCreateClient == nil {
+		return nil, fmt.Errorf("Load Balancer API client has not been initialized. Ensure the `lbaas_endpoint` for the Load Balancer Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.lbaasCreateClient, nil
+}
@@ -0,0 +1,166 @@
+package opc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// The credentials file read when config_file isn't set
+	defaultCredentialsFile = "~/.opc/config"
+	// The profile used when profile isn't set
+	defaultProfile = "default"
+)
+
+// credentialsProfile holds the settings of a named profile in the credentials file
+type credentialsProfile struct {
+	IdentityDomain   string `json:"identity_domain"`
+	User             string `json:"user"`
+	Password         string `json:"password"`
+	PasswordCommand  string `json:"password_command"`
+	Endpoint         string `json:"endpoint"`
+	StorageEndpoint  string `json:"storage_endpoint"`
+	StorageServiceID string `json:"storage_service_id"`
+	LBaaSEndpoint    string `json:"lbaas_endpoint"`
+}
+
+// Reads a profile from the credentials file. A missing file or profile is only an error when
+// the file or profile was chosen explicitly, otherwise nil is returned.
+func loadCredentialsProfile(path, name string, explicit bool) (*credentialsProfile, error) {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error expanding homedir in config_file (%s): %s", path, err)
+	}
+	data, err := ioutil.ReadFile(expanded)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Error reading credentials file (%s): %s", path, err)
+	}
+
+	profiles, err := parseCredentialsFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing credentials file (%s): %s", path, err)
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		if !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Profile %q not found in credentials file (%s)", name, path)
+	}
+	return profile, nil
+}
+
+// Parses the profiles in a credentials file, either a JSON object keyed by the profile names,
+// or an INI file with a section for each profile
+func parseCredentialsFile(data []byte) (map[string]*credentialsProfile, error) {
+	profiles := make(map[string]*credentialsProfile)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &profiles); err != nil {
+			return nil, err
+		}
+		return profiles, nil
+	}
+
+	var profile *credentialsProfile
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";"):
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			profile = &credentialsProfile{}
+			profiles[name] = profile
+			continue
+		}
+
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected a [profile] or key = value", line)
+		}
+		if profile == nil {
+			return nil, fmt.Errorf("line %d: %s must be in a [profile]", line, strings.TrimSpace(parts[0]))
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		switch key {
+		case "identity_domain":
+			profile.IdentityDomain = value
+		case "user":
+			profile.User = value
+		case "password":
+			profile.Password = value
+		case "password_command":
+			profile.PasswordCommand = value
+		case "endpoint":
+			profile.Endpoint = value
+		case "storage_endpoint":
+			profile.StorageEndpoint = value
+		case "storage_service_id":
+			profile.StorageServiceID = value
+		case "lbaas_endpoint":
+			profile.LBaaSEndpoint = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %s", line, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// Fills in the settings which aren't set in the provider or the environment from the profile
+func (c *Config) applyProfile(profile *credentialsProfile) error {
+	if profile == nil {
+		return nil
+	}
+	setDefault := func(value *string, def string) {
+		if *value == "" {
+			*value = def
+		}
+	}
+	setDefault(&c.IdentityDomain, profile.IdentityDomain)
+	setDefault(&c.User, profile.User)
+	setDefault(&c.Password, profile.Password)
+	setDefault(&c.Endpoint, profile.Endpoint)
+	setDefault(&c.StorageEndpoint, profile.StorageEndpoint)
+	setDefault(&c.StorageServiceID, profile.StorageServiceID)
+	setDefault(&c.LBaaSEndpoint, profile.LBaaSEndpoint)
+
+	if c.Password == "" && profile.PasswordCommand != "" {
+		password, err := runPasswordCommand(profile.PasswordCommand)
+		if err != nil {
+			return err
+		}
+		c.Password = password
+	}
+	return nil
+}
+
+// Runs the command with the shell, returning its output as the password
+func runPasswordCommand(command string) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.Command(shell, flag, command)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error running password_command: %s", err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
@@ -0,0 +1,185 @@
+package opc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestParseCredentialsFile(t *testing.T) {
+	expected := map[string]*credentialsProfile{
+		"default": {
+			IdentityDomain: "mydomain",
+			User:           "user@example.com",
+			Password:       "secret",
+			Endpoint:       "https://compute.example.com",
+		},
+		"storage": {
+			IdentityDomain:   "mydomain",
+			User:             "storage@example.com",
+			PasswordCommand:  "pass show opc",
+			StorageEndpoint:  "https://storage.example.com",
+			StorageServiceID: "mydomain",
+		},
+	}
+
+	ini := `
+# Comments and blank lines are ignored
+[default]
+identity_domain = mydomain
+user            = user@example.com
+password        = secret
+endpoint        = https://compute.example.com
+
+; The password is read from a password manager
+[storage]
+identity_domain    = mydomain
+user               = storage@example.com
+password_command   = pass show opc
+storage_endpoint   = https://storage.example.com
+storage_service_id = mydomain
+`
+	json := `{
+  "default": {
+    "identity_domain": "mydomain",
+    "user": "user@example.com",
+    "password": "secret",
+    "endpoint": "https://compute.example.com"
+  },
+  "storage": {
+    "identity_domain": "mydomain",
+    "user": "storage@example.com",
+    "password_command": "pass show opc",
+    "storage_endpoint": "https://storage.example.com",
+    "storage_service_id": "mydomain"
+  }
+}`
+
+	for _, data := range []string{ini, json} {
+		profiles, err := parseCredentialsFile([]byte(data))
+		if err != nil {
+			t.Fatalf("Error parsing credentials file: %s", err)
+		}
+		if !reflect.DeepEqual(profiles, expected) {
+			t.Fatalf("Expected %#v, got %#v", expected, profiles)
+		}
+	}
+}
+
+func TestParseCredentialsFile_invalid(t *testing.T) {
+	cases := map[string]string{
+		"user = outside":                    "must be in a [profile]",
+		"[default]\nuser":                   "expected a [profile] or key = value",
+		"[default]\nusername = me":          "unknown key username",
+		`{"default": {"user": ["a", "b"]}}`: "cannot unmarshal",
+	}
+
+	for data, expected := range cases {
+		_, err := parseCredentialsFile([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected an error containing %q parsing %q, got %v", expected, data, err)
+		}
+	}
+}
+
+func TestProviderConfig_profile(t *testing.T) {
+	defer testUnsetEnv("OPC_USERNAME", "OPC_PASSWORD", "OPC_IDENTITY_DOMAIN", "OPC_ENDPOINT",
+		"OPC_STORAGE_ENDPOINT", "OPC_STORAGE_SERVICE_ID", "OPC_LBAAS_ENDPOINT", "OPC_PROFILE", "OPC_CONFIG_FILE")()
+
+	dir, err := ioutil.TempDir("", "opc-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	data := `
+[default]
+identity_domain = default-domain
+user            = default-user
+password        = default-password
+
+[other]
+identity_domain  = other-domain
+user             = other-user
+password_command = echo other-password
+endpoint         = https://compute.example.com
+
+[empty]
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default profile is used when no profile is given
+	config := testProviderConfig(t, map[string]interface{}{"config_file": path})
+	if config.User != "default-user" || config.Password != "default-password" || config.IdentityDomain != "default-domain" {
+		t.Fatalf("Expected the default profile, got %#v", config)
+	}
+
+	// The provider block takes precedence over the profile, and the password command is run
+	config = testProviderConfig(t, map[string]interface{}{"config_file": path, "profile": "other", "user": "provider-user"})
+	if config.User != "provider-user" || config.Password != "other-password" || config.Endpoint != "https://compute.example.com" {
+		t.Fatalf("Expected the other profile and provider user, got %#v", config)
+	}
+
+	// Environment variables take precedence over the profile too
+	os.Setenv("OPC_PROFILE", "other")
+	os.Setenv("OPC_IDENTITY_DOMAIN", "env-domain")
+	config = testProviderConfig(t, map[string]interface{}{"config_file": path})
+	if config.User != "other-user" || config.IdentityDomain != "env-domain" {
+		t.Fatalf("Expected the other profile and environment domain, got %#v", config)
+	}
+
+	// A profile which was asked for must exist
+	os.Setenv("OPC_PROFILE", "missing")
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{"config_file": path})
+	if _, err := providerConfig(d); err == nil || !strings.Contains(err.Error(), `Profile "missing" not found`) {
+		t.Fatalf("Expected a missing profile error, got %v", err)
+	}
+
+	// A missing file is only an error when it was asked for
+	if profile, err := loadCredentialsProfile(filepath.Join(dir, "missing"), defaultProfile, false); profile != nil || err != nil {
+		t.Fatalf("Expected no profile or error, got %#v, %v", profile, err)
+	}
+
+	// The credentials are still required
+	os.Setenv("OPC_PROFILE", "empty")
+	os.Unsetenv("OPC_IDENTITY_DOMAIN")
+	d = schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{"config_file": path})
+	if _, err := providerConfig(d); err == nil || !strings.Contains(err.Error(), "user must be set") {
+		t.Fatalf("Expected a missing user error, got %v", err)
+	}
+}
+
+func testProviderConfig(t *testing.T, raw map[string]interface{}) *Config {
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, raw)
+	config, err := providerConfig(d)
+	if err != nil {
+		t.Fatalf("Error reading the provider config: %s", err)
+	}
+	return config
+}
+
+// Unsets the environment variables, returning a function which restores them
+func testUnsetEnv(keys ...string) func() {
+	values := make(map[string]string)
+	for _, key := range keys {
+		if v, ok := os.LookupEnv(key); ok {
+			values[key] = v
+		}
+		os.Unsetenv(key)
+	}
+	return func() {
+		for _, key := range keys {
+			os.Unsetenv(key)
+			if v, ok := values[key]; ok {
+				os.Setenv(key, v)
+			}
+		}
+	}
+}
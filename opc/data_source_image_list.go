@@ -0,0 +1,148 @@
+package opc
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/structure"
+)
+
+func dataSourceImageList() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceImageListRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"most_recent": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			// Computed Attributes
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"default": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"machine_images": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"entries": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"version": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"machine_images": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"attributes": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"uri": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"uri": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceImageListRead(d *schema.ResourceData, meta interface{}) error {
+	computeClient, err := meta.(*Client).getComputeClient()
+	if err != nil {
+		return err
+	}
+	resClient := computeClient.ImageList()
+
+	name := d.Get("name").(string)
+	input := compute.GetImageListInput{
+		Name: name,
+	}
+
+	log.Printf("[DEBUG] Reading image list %s", name)
+	result, err := resClient.GetImageList(&input)
+	if err != nil {
+		return fmt.Errorf("Error reading image list %s: %s", name, err)
+	}
+
+	entries := result.Entries
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Version < entries[j].Version
+	})
+
+	// The selected entry is the default one, or the highest version if the most recent is wanted
+	var selected *compute.ImageListEntry
+	flattenedEntries := make([]map[string]interface{}, 0, len(entries))
+	for i := range entries {
+		entry := &entries[i]
+		attrs, err := structure.FlattenJsonToString(entry.Attributes)
+		if err != nil {
+			return err
+		}
+		flattenedEntries = append(flattenedEntries, map[string]interface{}{
+			"version":        entry.Version,
+			"machine_images": entry.MachineImages,
+			"attributes":     attrs,
+			"uri":            entry.URI,
+		})
+
+		if d.Get("most_recent").(bool) || entry.Version == result.Default {
+			selected = entry
+		}
+	}
+
+	if selected == nil {
+		if len(entries) == 0 {
+			return fmt.Errorf("Image list %s has no entries", name)
+		}
+		return fmt.Errorf("Image list %s has no entry for its default version %d", name, result.Default)
+	}
+
+	d.SetId(name)
+	d.Set("description", result.Description)
+	d.Set("default", result.Default)
+	d.Set("uri", result.URI)
+	d.Set("version", selected.Version)
+	if err := d.Set("machine_images", selected.MachineImages); err != nil {
+		return err
+	}
+	return d.Set("entries", flattenedEntries)
+}
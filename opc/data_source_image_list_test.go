@@ -0,0 +1,72 @@
+package opc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceImageList_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resName := "data.opc_compute_image_list.default"
+	recentName := "data.opc_compute_image_list.most_recent"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceImageListBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "description", "Acceptance Test TestAccOPCDataSourceImageList_basic"),
+					resource.TestCheckResourceAttr(resName, "default", "1"),
+					resource.TestCheckResourceAttr(resName, "version", "1"),
+					resource.TestCheckResourceAttr(resName, "machine_images.#", "1"),
+					resource.TestCheckResourceAttr(resName, "machine_images.0", "/oracle/public/oel_6.7_apaas_16.4.5_1610211300"),
+					resource.TestCheckResourceAttr(resName, "entries.#", "2"),
+					resource.TestCheckResourceAttr(resName, "entries.0.version", "1"),
+					resource.TestCheckResourceAttr(resName, "entries.1.version", "2"),
+					resource.TestCheckResourceAttr(resName, "entries.1.machine_images.#", "2"),
+					resource.TestCheckResourceAttr(recentName, "version", "2"),
+					resource.TestCheckResourceAttr(recentName, "machine_images.#", "2"),
+					resource.TestCheckResourceAttr(recentName, "machine_images.1", "/oracle/public/OL_5.11_UEKR2_i386-17.2.2-20170405-205607"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceImageListBasic(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_image_list" "test" {
+  name        = "test-acc-image-list-data-source-%d"
+  description = "Acceptance Test TestAccOPCDataSourceImageList_basic"
+  default     = 1
+}
+
+resource "opc_compute_image_list_entry" "v1" {
+  name           = "${opc_compute_image_list.test.name}"
+  machine_images = ["/oracle/public/oel_6.7_apaas_16.4.5_1610211300"]
+  version        = 1
+}
+
+resource "opc_compute_image_list_entry" "v2" {
+  name           = "${opc_compute_image_list_entry.v1.name}"
+  machine_images = [
+    "/oracle/public/oel_6.7_apaas_16.4.5_1610211300",
+    "/oracle/public/OL_5.11_UEKR2_i386-17.2.2-20170405-205607"
+  ]
+  version        = 2
+}
+
+data "opc_compute_image_list" "default" {
+  name = "${opc_compute_image_list_entry.v2.name}"
+}
+
+data "opc_compute_image_list" "most_recent" {
+  name        = "${opc_compute_image_list_entry.v2.name}"
+  most_recent = true
+}`, rInt)
+}
@@ -0,0 +1,57 @@
+package opc
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceInstanceConsole() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceInstanceConsoleRead,
+
+		Schema: map[string]*schema.Schema{
+			"instance_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"instance_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			// Computed Values returned from the data source lookup
+			"output": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"timestamp": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceInstanceConsoleRead(d *schema.ResourceData, meta interface{}) error {
+	instanceActions, err := meta.(*Client).getInstanceActionsClient()
+	if err != nil {
+		return err
+	}
+
+	instanceName := d.Get("instance_name").(string)
+	instanceID := d.Get("instance_id").(string)
+
+	console, err := instanceActions.GetInstanceConsole(instanceName, instanceID)
+	if err != nil {
+		return fmt.Errorf("Error reading the console of instance %s: %s", instanceName, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", instanceName, instanceID))
+	d.Set("output", console.Output)
+	d.Set("timestamp", console.Timestamp)
+
+	return nil
+}
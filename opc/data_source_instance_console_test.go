@@ -0,0 +1,45 @@
+package opc
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceInstanceConsole_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resName := "data.opc_compute_instance_console.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceInstanceConsoleBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(resName, "output", regexp.MustCompile(fmt.Sprintf("Booting instance .*acc-test-instance-%d", rInt))),
+					resource.TestCheckResourceAttrSet(resName, "timestamp"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceInstanceConsoleBasic(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-instance-%d"
+  label      = "TestAccOPCDataSourceInstanceConsole_basic"
+  shape      = "oc3"
+  image_list = "%s"
+}
+
+data "opc_compute_instance_console" "test" {
+  instance_name = "${opc_compute_instance.test.name}"
+  instance_id   = "${opc_compute_instance.test.id}"
+}`, rInt, TestImageList)
+}
@@ -0,0 +1,393 @@
+package opc
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/go-oracle-terraform/client"
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceInstances() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceInstancesRead,
+
+		Schema: map[string]*schema.Schema{
+			"tags": tagsOptionalSchema(),
+
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+
+			"shape": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"state": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(compute.InstanceRunning),
+					string(compute.InstanceInitializing),
+					string(compute.InstancePreparing),
+					string(compute.InstanceStarting),
+					string(compute.InstanceStopping),
+					string(compute.InstanceShutdown),
+					string(compute.InstanceQueued),
+					string(compute.InstanceError),
+				}, false),
+			},
+
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"instances": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"shape": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"instance_attributes": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"boot_order": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeInt},
+						},
+
+						"hostname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"image_list": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"label": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"networking_info": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"dns": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+
+									"index": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+
+									"ip_address": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+
+									"ip_network": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+
+									"is_default_gateway": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+
+									"mac_address": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+
+									"name_servers": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+
+									"nat": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+
+									"search_domains": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+
+									"sec_lists": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+
+									"shared_network": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+
+									"vnic": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+
+									"vnic_sets": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+								},
+							},
+						},
+
+						"reverse_dns": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"ssh_keys": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"storage": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"index": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+
+									"volume": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+
+									"name": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+
+						"tags": tagsComputedSchema(),
+
+						"availability_domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"entry": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"fingerprint": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"fqdn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"image_format": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"ip_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"placement_requirements": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"platform": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"priority": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"quota_reservation": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"relationships": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"resolvers": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"site": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"start_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"vcable": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"virtio": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"vnc_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceInstancesRead(d *schema.ResourceData, meta interface{}) error {
+	listClient, err := meta.(*Client).getComputeListClient()
+	if err != nil {
+		return err
+	}
+	computeClient, err := meta.(*Client).getComputeClient()
+	if err != nil {
+		return err
+	}
+	resClient := computeClient.Instances()
+
+	input := &computeListInput{
+		Tags: getStringList(d, "tags"),
+	}
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+	shape := d.Get("shape").(string)
+	state := d.Get("state").(string)
+
+	log.Printf("[DEBUG] Listing instances: %+v", input)
+	instances, err := listClient.ListInstances(input)
+	if err != nil {
+		return fmt.Errorf("Error listing instances: %s", err)
+	}
+
+	names := make([]string, 0)
+	result := make([]map[string]interface{}, 0)
+	for _, listed := range instances {
+		if nameRegex != nil && !nameRegex.MatchString(listed.Name) {
+			continue
+		}
+		if shape != "" && listed.Shape != shape {
+			continue
+		}
+		if state != "" && string(listed.State) != state {
+			continue
+		}
+
+		// Read each instance as opc_compute_instance does, so the references are unqualified
+		instance, err := resClient.GetInstance(&compute.GetInstanceInput{
+			Name: listed.Name,
+			ID:   listed.ID,
+		})
+		if err != nil {
+			// The instance was deleted since it was listed
+			if client.WasNotFoundError(err) {
+				log.Printf("[DEBUG] Instance %s no longer exists, skipping it", listed.Name)
+				continue
+			}
+			return fmt.Errorf("Error reading instance %s: %s", listed.Name, err)
+		}
+
+		v, err := flattenInstance(instance)
+		if err != nil {
+			return err
+		}
+		names = append(names, instance.Name)
+		result = append(result, v)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i]["name"].(string) < result[j]["name"].(string)
+	})
+	sort.Strings(names)
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(fmt.Sprintf("%v", names))))
+	if err := d.Set("names", names); err != nil {
+		return err
+	}
+	return d.Set("instances", result)
+}
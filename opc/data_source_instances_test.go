@@ -0,0 +1,67 @@
+package opc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceInstances_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resName := "data.opc_compute_instances.web"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceInstancesBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "instances.#", "2"),
+					resource.TestCheckResourceAttr(resName, "names.#", "2"),
+					resource.TestCheckResourceAttr(resName, "names.0", fmt.Sprintf("acc-test-instances-web-%d-0", rInt)),
+					resource.TestCheckResourceAttr(resName, "instances.0.shape", "oc3"),
+					resource.TestCheckResourceAttr(resName, "instances.0.state", "running"),
+					resource.TestCheckResourceAttrPair(resName, "instances.0.id", "opc_compute_instance.web.0", "id"),
+					resource.TestCheckResourceAttrPair(resName, "instances.0.ip_address", "opc_compute_instance.web.0", "ip_address"),
+					resource.TestCheckResourceAttr("data.opc_compute_instances.db", "instances.#", "1"),
+					resource.TestCheckResourceAttr("data.opc_compute_instances.db", "instances.0.tags.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceInstancesBasic(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "web" {
+  count      = 2
+  name       = "acc-test-instances-web-%d-${count.index}"
+  label      = "TestAccOPCDataSourceInstances_basic"
+  shape      = "oc3"
+  image_list = "%s"
+  tags       = ["acc-test-%d", "role=web"]
+}
+
+resource "opc_compute_instance" "db" {
+  name       = "acc-test-instances-db-%d"
+  label      = "TestAccOPCDataSourceInstances_basic"
+  shape      = "oc3"
+  image_list = "%s"
+  tags       = ["acc-test-%d", "role=db"]
+}
+
+data "opc_compute_instances" "web" {
+  tags  = ["role=web", "acc-test-%d"]
+  shape = "${element(opc_compute_instance.web.*.shape, 0)}"
+}
+
+data "opc_compute_instances" "db" {
+  name_regex = "^${opc_compute_instance.db.name}$"
+  shape      = "oc3"
+  state      = "running"
+}
+`, rInt, TestImageList, rInt, rInt, TestImageList, rInt, rInt)
+}
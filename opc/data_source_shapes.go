@@ -0,0 +1,170 @@
+package opc
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceShapes() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceShapesRead,
+
+		Schema: map[string]*schema.Schema{
+			"min_cpus": {
+				Type:     schema.TypeFloat,
+				Optional: true,
+			},
+
+			"min_ram": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"shapes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"cpus": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+
+						"ram": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"gpus": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"io": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"root_disk_size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"is_root_ssd": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"ssd_data_size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"nds_iops_limit": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceShapesRead(d *schema.ResourceData, meta interface{}) error {
+	listClient, err := meta.(*Client).getComputeListClient()
+	if err != nil {
+		return err
+	}
+
+	log.Print("[DEBUG] Listing shapes")
+	shapes, err := listClient.ListShapes()
+	if err != nil {
+		return fmt.Errorf("Error listing shapes: %s", err)
+	}
+
+	minCPUs := d.Get("min_cpus").(float64)
+	minRAM := d.Get("min_ram").(int)
+
+	matched := make([]computeShape, 0, len(shapes))
+	for _, shape := range shapes {
+		if shape.CPUs < minCPUs || shape.RAM < minRAM {
+			continue
+		}
+		matched = append(matched, shape)
+	}
+
+	// Smallest first, so the first shape is the smallest that fits
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].CPUs != matched[j].CPUs {
+			return matched[i].CPUs < matched[j].CPUs
+		}
+		if matched[i].RAM != matched[j].RAM {
+			return matched[i].RAM < matched[j].RAM
+		}
+		return matched[i].Name < matched[j].Name
+	})
+
+	names := make([]string, 0, len(matched))
+	result := make([]map[string]interface{}, 0, len(matched))
+	for _, shape := range matched {
+		names = append(names, shape.Name)
+		result = append(result, map[string]interface{}{
+			"name":           shape.Name,
+			"cpus":           shape.CPUs,
+			"ram":            shape.RAM,
+			"gpus":           shape.GPUs,
+			"io":             shape.IO,
+			"root_disk_size": shape.RootDiskSize,
+			"is_root_ssd":    shape.IsRootSSD,
+			"ssd_data_size":  shape.SSDDataSize,
+			"nds_iops_limit": shape.NDSIOPSLimit,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(names, ","))))
+	if err := d.Set("names", names); err != nil {
+		return err
+	}
+	return d.Set("shapes", result)
+}
+
+// Checks the shape is available to the account while planning, rather than partway through
+// an apply. The check is skipped if the shapes can't be listed.
+func validateShapeAvailable(meta interface{}, shape string) error {
+	client, ok := meta.(*Client)
+	if !ok || client.computeListClient == nil {
+		return nil
+	}
+	shapes, err := client.computeListClient.getCachedShapes()
+	if err != nil {
+		log.Printf("[WARN] Unable to list shapes, skipping validation of shape %q: %s", shape, err)
+		return nil
+	}
+
+	names := make([]string, 0, len(shapes))
+	for _, s := range shapes {
+		if s.Name == shape {
+			return nil
+		}
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("Shape %q is not available, expected one of: %s", shape, strings.Join(names, ", "))
+}
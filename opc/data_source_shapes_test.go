@@ -0,0 +1,91 @@
+package opc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccOPCDataSourceShapes_basic(t *testing.T) {
+	resName := "data.opc_compute_shapes.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceShapesBasic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckShapesSorted(resName, 4, 20000),
+					resource.TestCheckResourceAttrPair(resName, "names.0", resName, "shapes.0.name"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_invalidShape(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccInstanceInvalidShape(rInt),
+				ExpectError: regexp.MustCompile(`Shape "not-a-shape" is not available`),
+			},
+		},
+	})
+}
+
+// Checks every shape meets the minimum requirements, and that they're ordered smallest first
+func testAccCheckShapesSorted(resName string, minCPUs float64, minRAM int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resName]
+		if !ok {
+			return fmt.Errorf("Data source %s not found", resName)
+		}
+		count, err := strconv.Atoi(rs.Primary.Attributes["shapes.#"])
+		if err != nil || count == 0 {
+			return fmt.Errorf("Expected at least one shape, got %q", rs.Primary.Attributes["shapes.#"])
+		}
+
+		var lastCPUs float64
+		for i := 0; i < count; i++ {
+			cpus, _ := strconv.ParseFloat(rs.Primary.Attributes[fmt.Sprintf("shapes.%d.cpus", i)], 64)
+			ram, _ := strconv.Atoi(rs.Primary.Attributes[fmt.Sprintf("shapes.%d.ram", i)])
+			if cpus < minCPUs || ram < minRAM {
+				return fmt.Errorf("Shape %d has %v CPUs and %d MB RAM, less than the minimum", i, cpus, ram)
+			}
+			if cpus < lastCPUs {
+				return fmt.Errorf("Shape %d has fewer CPUs than the shape before it", i)
+			}
+			lastCPUs = cpus
+		}
+		return nil
+	}
+}
+
+const testAccDataSourceShapesBasic = `
+data "opc_compute_shapes" "test" {
+  min_cpus = 4
+  min_ram  = 20000
+}
+`
+
+func testAccInstanceInvalidShape(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-instance-%d"
+  label      = "TestAccOPCInstance_invalidShape"
+  shape      = "not-a-shape"
+  image_list = "%s"
+}
+`, rInt, TestImageList)
+}
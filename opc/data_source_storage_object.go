@@ -0,0 +1,191 @@
+package opc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/hashicorp/go-oracle-terraform/storage"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mitchellh/go-homedir"
+)
+
+// A single byte range, as accepted by the Range header
+var storageRangeRegexp = regexp.MustCompile(`^bytes=(\d+-\d*|-\d+)$`)
+
+func dataSourceStorageObject() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceStorageObjectRead,
+
+		Schema: map[string]*schema.Schema{
+			"container": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"range": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(storageRangeRegexp, "must be a byte range, e.g. bytes=0-99, bytes=100- or bytes=-100"),
+			},
+
+			"max_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"output_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// Computed Attributes
+			"content": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"content_length": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"content_range": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"content_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"metadata": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceStorageObjectRead(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	input := &storage.GetObjectInput{
+		Container: d.Get("container").(string),
+		Name:      d.Get("name").(string),
+		Range:     d.Get("range").(string),
+	}
+	id := fmt.Sprintf("%s/%s", input.Container, input.Name)
+
+	log.Printf("[DEBUG] Reading storage object %s", id)
+	result, err := storageClient.GetObjectContent(input)
+	if err != nil {
+		return fmt.Errorf("Error reading storage object %s: %s", id, err)
+	}
+	defer result.Body.Close()
+
+	// Never read more than the limit, in case the content length isn't known up front
+	body := io.Reader(result.Body)
+	maxSize := int64(d.Get("max_size").(int))
+	if maxSize > 0 {
+		if result.ContentLength > maxSize {
+			return fmt.Errorf("Storage object %s is %d bytes, larger than the max_size of %d bytes", id, result.ContentLength, maxSize)
+		}
+		body = io.LimitReader(result.Body, maxSize+1)
+	}
+
+	var size int64
+	content := ""
+	if v, ok := d.GetOk("output_file"); ok {
+		size, err = writeStorageObjectFile(v.(string), body, maxSize)
+		if err != nil {
+			return fmt.Errorf("Error writing storage object %s to %s: %s", id, v.(string), err)
+		}
+	} else {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("Error reading storage object %s: %s", id, err)
+		}
+		if !utf8.Valid(data) {
+			return fmt.Errorf("Storage object %s is not valid UTF-8, use output_file to download binary content", id)
+		}
+		size = int64(len(data))
+		content = string(data)
+	}
+	if maxSize > 0 && size > maxSize {
+		return fmt.Errorf("Storage object %s is larger than the max_size of %d bytes", id, maxSize)
+	}
+
+	d.SetId(id)
+	d.Set("content", content)
+	d.Set("content_length", size)
+	d.Set("content_range", result.ContentRange)
+	d.Set("content_type", result.ContentType)
+	d.Set("etag", result.Etag)
+	d.Set("last_modified", result.LastModified)
+	if err := d.Set("metadata", result.Metadata); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Writes the content to the file, returning the number of bytes written. The content is written to a
+// temporary file in the same directory, which replaces the file once all of the content is written, so
+// the file is never left partly written.
+func writeStorageObjectFile(path string, content io.Reader, maxSize int64) (int64, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return 0, err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(file, content)
+	if err == nil && maxSize > 0 && size > maxSize {
+		err = fmt.Errorf("the object is larger than the max_size of %d bytes", maxSize)
+	}
+	if err == nil {
+		// Temporary files are only readable by the owner
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return 0, err
+	}
+	return size, nil
+}
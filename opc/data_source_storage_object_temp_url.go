@@ -0,0 +1,185 @@
+package opc
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceStorageObjectTempURL() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceStorageObjectTempURLRead,
+
+		Schema: map[string]*schema.Schema{
+			"container": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"prefix"},
+			},
+
+			"prefix": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"name"},
+			},
+
+			"key": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+
+			"method": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  http.MethodGet,
+				ValidateFunc: validation.StringInSlice([]string{
+					http.MethodGet,
+					http.MethodHead,
+					http.MethodPut,
+					http.MethodPost,
+					http.MethodDelete,
+				}, false),
+			},
+
+			"duration": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Default:       "1h",
+				ValidateFunc:  validateDuration,
+				ConflictsWith: []string{"expires"},
+			},
+
+			// A fixed expiry keeps the URL the same every time it's read, unlike a duration
+			"expires": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ValidateFunc:  validation.IntAtLeast(1),
+				ConflictsWith: []string{"duration"},
+			},
+
+			"ip_range": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// Computed Attributes
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"query": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"signature": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// The URL is signed locally, the storage client only supplies the endpoint and account
+func dataSourceStorageObjectTempURLRead(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	container := d.Get("container").(string)
+	name := d.Get("name").(string)
+	prefix, isPrefix := d.GetOk("prefix")
+	if isPrefix {
+		name = prefix.(string)
+	} else if name == "" {
+		return fmt.Errorf("One of `name` or `prefix` must be specified")
+	}
+
+	var expires int64
+	if v, ok := d.GetOk("expires"); ok {
+		expires = int64(v.(int))
+	} else {
+		duration, err := time.ParseDuration(d.Get("duration").(string))
+		if err != nil {
+			return err
+		}
+		expires = time.Now().Add(duration).Unix()
+	}
+
+	path := storageClient.path(container, name)
+	signature := storageTempURLSignature(&storageTempURLInput{
+		Key:      d.Get("key").(string),
+		Method:   d.Get("method").(string),
+		Expires:  expires,
+		Path:     path,
+		IPRange:  d.Get("ip_range").(string),
+		IsPrefix: isPrefix,
+	})
+
+	query := url.Values{}
+	query.Set("temp_url_sig", signature)
+	query.Set("temp_url_expires", strconv.FormatInt(expires, 10))
+	if v, ok := d.GetOk("ip_range"); ok {
+		query.Set("temp_url_ip_range", v.(string))
+	}
+	if isPrefix {
+		query.Set("temp_url_prefix", name)
+	}
+	tempURL := storageClient.endpoint.ResolveReference(&url.URL{Path: path, RawQuery: query.Encode()})
+
+	d.SetId(path)
+	d.Set("url", tempURL.String())
+	d.Set("query", query.Encode())
+	d.Set("signature", signature)
+	d.Set("expires", expires)
+
+	return nil
+}
+
+// storageTempURLInput is signed to create a temporary URL
+type storageTempURLInput struct {
+	// The temp URL key of the container
+	Key    string
+	Method string
+	// The time the URL expires, in UNIX Epoch time
+	Expires int64
+	// The path of the object, or the prefix, e.g. /v1/Storage-domain/container/object
+	Path string
+	// Only allow requests from this IP address or CIDR block
+	IPRange string
+	// Whether the path is a prefix of the names of the objects the URL can be used for
+	IsPrefix bool
+}
+
+// Returns the HMAC-SHA1 signature of a temporary URL
+func storageTempURLSignature(input *storageTempURLInput) string {
+	path := input.Path
+	if input.IsPrefix {
+		path = "prefix:" + path
+	}
+	body := fmt.Sprintf("%s\n%d\n%s", input.Method, input.Expires, path)
+	if input.IPRange != "" {
+		body = fmt.Sprintf("ip=%s\n%s", input.IPRange, body)
+	}
+
+	mac := hmac.New(sha1.New, []byte(input.Key))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,179 @@
+package opc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestStorageTempURLSignature(t *testing.T) {
+	cases := []struct {
+		Input    storageTempURLInput
+		Expected string
+	}{
+		{
+			storageTempURLInput{Key: "mykey", Method: "GET", Expires: 1440619048, Path: "/v1/AUTH_account/container/object"},
+			"da720a7e11f9f2c7b0fe46039811229c1c7a9cb4",
+		},
+		{
+			storageTempURLInput{Key: "mykey", Method: "GET", Expires: 1440619048, Path: "/v1/AUTH_account/container/object", IPRange: "1.2.3.4"},
+			"6b3b11806dbb5da5c353cdcc06324907b72f38af",
+		},
+		{
+			storageTempURLInput{Key: "mykey", Method: "GET", Expires: 1440619048, Path: "/v1/AUTH_account/container/pre", IsPrefix: true},
+			"0629990fad99d751011c4b9097c054eb05fc6446",
+		},
+	}
+
+	for _, tc := range cases {
+		if signature := storageTempURLSignature(&tc.Input); signature != tc.Expected {
+			t.Fatalf("Expected signature %s for %+v, got %s", tc.Expected, tc.Input, signature)
+		}
+	}
+}
+
+func TestAccOPCDataSourceStorageObjectTempURL_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceStorageObjectTempURLObject(rInt),
+			},
+			{
+				Config: testAccDataSourceStorageObjectTempURLBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.opc_storage_object_temp_url.test", "signature"),
+					resource.TestCheckResourceAttrSet("data.opc_storage_object_temp_url.test", "expires"),
+					testAccCheckTempURLContent("data.opc_storage_object_temp_url.test", "", http.StatusOK, "#!/bin/sh"),
+					testAccCheckTempURLContent("data.opc_storage_object_temp_url.prefix", "bootstrap.sh", http.StatusOK, "#!/bin/sh"),
+					// A URL signed with the wrong key is refused
+					testAccCheckTempURLContent("data.opc_storage_object_temp_url.wrong_key", "", http.StatusUnauthorized, ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCDataSourceStorageObjectTempURL_expires(t *testing.T) {
+	rInt := acctest.RandInt()
+	expires := time.Now().Add(time.Hour).Unix()
+	config := testAccDataSourceStorageObjectTempURLExpires(rInt, expires)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.opc_storage_object_temp_url.test", "expires", strconv.FormatInt(expires, 10)),
+					testAccCheckTempURLContent("data.opc_storage_object_temp_url.test", "", http.StatusOK, "#!/bin/sh"),
+				),
+			},
+			{
+				// The URL is the same when it's read again, so the object using it isn't replaced
+				PreConfig: func() { time.Sleep(time.Second) },
+				Config:    config,
+				PlanOnly:  true,
+			},
+		},
+	})
+}
+
+// Fetches the temporary URL, appending the object name for a prefix, checking the status and content of the response
+func testAccCheckTempURLContent(resName, object string, status int, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resName]
+		if !ok {
+			return fmt.Errorf("Data source %s not found", resName)
+		}
+		tempURL, err := url.Parse(rs.Primary.Attributes["url"])
+		if err != nil {
+			return err
+		}
+		tempURL.Path += object
+
+		resp, err := http.Get(tempURL.String())
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != status {
+			return fmt.Errorf("Expected status %d fetching %s, got %d: %s", status, tempURL, resp.StatusCode, body)
+		}
+		if expected != "" && string(body) != expected {
+			return fmt.Errorf("Expected content %q from %s, got %q", expected, tempURL, body)
+		}
+		return nil
+	}
+}
+
+func testAccDataSourceStorageObjectTempURLObject(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_storage_container" "test" {
+  name        = "acc-test-temp-url-%d"
+  primary_key = "test-key-%d"
+}
+
+resource "opc_storage_object" "test" {
+  name      = "bootstrap/bootstrap.sh"
+  container = "${opc_storage_container.test.name}"
+  content   = "#!/bin/sh"
+}`, rInt, rInt)
+}
+
+func testAccDataSourceStorageObjectTempURLBasic(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "opc_storage_object_temp_url" "test" {
+  container = "${opc_storage_object.test.container}"
+  name      = "${opc_storage_object.test.name}"
+  key       = "${opc_storage_container.test.primary_key}"
+  duration  = "10m"
+}
+
+data "opc_storage_object_temp_url" "prefix" {
+  container = "${opc_storage_object.test.container}"
+  prefix    = "bootstrap/"
+  key       = "${opc_storage_container.test.primary_key}"
+}
+
+data "opc_storage_object_temp_url" "wrong_key" {
+  container = "${opc_storage_object.test.container}"
+  name      = "${opc_storage_object.test.name}"
+  key       = "not-the-key"
+}`, testAccDataSourceStorageObjectTempURLObject(rInt))
+}
+
+func testAccDataSourceStorageObjectTempURLExpires(rInt int, expires int64) string {
+	return fmt.Sprintf(`
+%s
+
+data "opc_storage_object_temp_url" "test" {
+  container = "${opc_storage_object.test.container}"
+  name      = "${opc_storage_object.test.name}"
+  key       = "${opc_storage_container.test.primary_key}"
+  expires   = %d
+}
+
+resource "opc_storage_object" "url" {
+  name      = "bootstrap-url"
+  container = "${opc_storage_container.test.name}"
+  content   = "${data.opc_storage_object_temp_url.test.url}"
+}`, testAccDataSourceStorageObjectTempURLObject(rInt), expires)
+}
@@ -0,0 +1,151 @@
+package opc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccOPCDataSourceStorageObject_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resName := "data.opc_storage_object.test"
+	rangeName := "data.opc_storage_object.range"
+	fileName := "data.opc_storage_object.file"
+
+	dir, err := ioutil.TempDir("", "opc-storage-object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	outputFile := filepath.Join(dir, "config.json")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceStorageObjectObject(rInt),
+			},
+			{
+				Config: testAccDataSourceStorageObjectBasic(rInt, outputFile),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "content", `{"environment": "test"}`),
+					resource.TestCheckResourceAttr(resName, "content_length", "23"),
+					resource.TestCheckResourceAttr(resName, "content_type", "application/json"),
+					resource.TestCheckResourceAttrPair(resName, "etag", "opc_storage_object.test", "etag"),
+					resource.TestCheckResourceAttrSet(resName, "last_modified"),
+					resource.TestCheckResourceAttr(resName, "metadata.%", "1"),
+					resource.TestCheckResourceAttr(resName, "metadata.Owner", "platform"),
+					resource.TestCheckResourceAttr(rangeName, "content", `"environment"`),
+					resource.TestCheckResourceAttr(rangeName, "content_length", "13"),
+					resource.TestCheckResourceAttr(rangeName, "content_range", "bytes 1-13/23"),
+					resource.TestCheckResourceAttr(fileName, "content", ""),
+					resource.TestCheckResourceAttr(fileName, "content_length", "23"),
+					testAccCheckFileContent(outputFile, `{"environment": "test"}`),
+				),
+			},
+			{
+				Config:      testAccDataSourceStorageObjectMaxSize(rInt),
+				ExpectError: regexp.MustCompile("larger than the max_size of 10 bytes"),
+			},
+		},
+	})
+}
+
+func TestWriteStorageObjectFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opc-storage-object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+
+	if size, err := writeStorageObjectFile(path, strings.NewReader("old"), 10); err != nil || size != 3 {
+		t.Fatalf("Expected 3 bytes to be written, got %d, %v", size, err)
+	}
+
+	// Content larger than the max size, e.g. when the length isn't known up front, leaves the file unchanged
+	_, err = writeStorageObjectFile(path, strings.NewReader("larger than ten bytes"), 10)
+	if err == nil || !strings.Contains(err.Error(), "larger than the max_size of 10 bytes") {
+		t.Fatalf("Expected a max_size error, got %v", err)
+	}
+	if err := testAccCheckFileContent(path, "old")(nil); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("Expected the temporary file to be removed, found %d files", len(files))
+	}
+}
+
+func testAccCheckFileContent(path, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if string(content) != expected {
+			return fmt.Errorf("Expected %s to contain %q, got %q", path, expected, string(content))
+		}
+		return nil
+	}
+}
+
+func testAccDataSourceStorageObjectObject(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_storage_container" "test" {
+  name = "acc-test-object-%d"
+}
+
+resource "opc_storage_object" "test" {
+  name         = "config/app.json"
+  container    = "${opc_storage_container.test.name}"
+  content_type = "application/json"
+  content      = "{\"environment\": \"test\"}"
+  metadata = {
+    Owner = "platform"
+  }
+}`, rInt)
+}
+
+func testAccDataSourceStorageObjectBasic(rInt int, outputFile string) string {
+	return fmt.Sprintf(`
+%s
+
+data "opc_storage_object" "test" {
+  container = "${opc_storage_object.test.container}"
+  name      = "${opc_storage_object.test.name}"
+  max_size  = 1024
+}
+
+data "opc_storage_object" "range" {
+  container = "${opc_storage_object.test.container}"
+  name      = "${opc_storage_object.test.name}"
+  range     = "bytes=1-13"
+}
+
+data "opc_storage_object" "file" {
+  container   = "${opc_storage_object.test.container}"
+  name        = "${opc_storage_object.test.name}"
+  output_file = "%s"
+}`, testAccDataSourceStorageObjectObject(rInt), outputFile)
+}
+
+func testAccDataSourceStorageObjectMaxSize(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "opc_storage_object" "test" {
+  container = "${opc_storage_object.test.container}"
+  name      = "${opc_storage_object.test.name}"
+  max_size  = 10
+}`, testAccDataSourceStorageObjectObject(rInt))
+}
@@ -0,0 +1,140 @@
+package opc
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceStorageObjects() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceStorageObjectsRead,
+
+		Schema: map[string]*schema.Schema{
+			"container": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"delimiter": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringLenBetween(1, 1),
+			},
+
+			"marker": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			// Computed Attributes
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"objects": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"etag": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"content_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"last_modified": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"common_prefixes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceStorageObjectsRead(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	input := &storageListObjectsInput{
+		Container: d.Get("container").(string),
+		Prefix:    d.Get("prefix").(string),
+		Delimiter: d.Get("delimiter").(string),
+		Marker:    d.Get("marker").(string),
+		Limit:     d.Get("limit").(int),
+	}
+
+	log.Printf("[DEBUG] Listing storage objects: %+v", input)
+	listing, err := storageClient.ListObjects(input)
+	if err != nil {
+		return fmt.Errorf("Error listing objects in container %s: %s", input.Container, err)
+	}
+
+	// The listing is ordered by name, with the common prefixes interleaved with the objects
+	names := make([]string, 0, len(listing))
+	objects := make([]map[string]interface{}, 0, len(listing))
+	prefixes := make([]string, 0)
+	for _, entry := range listing {
+		if entry.Subdir != "" {
+			prefixes = append(prefixes, entry.Subdir)
+			continue
+		}
+		names = append(names, entry.Name)
+		objects = append(objects, map[string]interface{}{
+			"name":          entry.Name,
+			"size":          entry.Bytes,
+			"etag":          entry.Hash,
+			"content_type":  entry.ContentType,
+			"last_modified": entry.LastModified,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%s/%d", input.Container, hashcode.String(fmt.Sprintf("%v%v", names, prefixes))))
+	if err := d.Set("names", names); err != nil {
+		return err
+	}
+	if err := d.Set("objects", objects); err != nil {
+		return err
+	}
+	return d.Set("common_prefixes", prefixes)
+}
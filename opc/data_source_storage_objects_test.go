@@ -0,0 +1,106 @@
+package opc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceStorageObjects_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resName := "data.opc_storage_objects.test"
+	prefixesName := "data.opc_storage_objects.prefixes"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceStorageObjectsObjects(rInt),
+			},
+			{
+				Config: testAccDataSourceStorageObjectsBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "names.#", "2"),
+					resource.TestCheckResourceAttr(resName, "names.0", "builds/1.0.0/app.tar.gz"),
+					resource.TestCheckResourceAttr(resName, "names.1", "builds/1.1.0/app.tar.gz"),
+					resource.TestCheckResourceAttr(resName, "objects.#", "2"),
+					resource.TestCheckResourceAttr(resName, "objects.1.name", "builds/1.1.0/app.tar.gz"),
+					resource.TestCheckResourceAttr(resName, "objects.1.size", "6"),
+					resource.TestCheckResourceAttr(resName, "objects.1.etag", "8a4fa81ecaa75f4ef61fca35e5dd0472"),
+					resource.TestCheckResourceAttr(resName, "objects.1.content_type", "application/gzip"),
+					resource.TestCheckResourceAttrSet(resName, "objects.1.last_modified"),
+					resource.TestCheckResourceAttr(resName, "common_prefixes.#", "0"),
+					resource.TestCheckResourceAttr(prefixesName, "names.#", "1"),
+					resource.TestCheckResourceAttr(prefixesName, "names.0", "README"),
+					resource.TestCheckResourceAttr(prefixesName, "common_prefixes.#", "1"),
+					resource.TestCheckResourceAttr(prefixesName, "common_prefixes.0", "builds/"),
+				),
+			},
+			{
+				Config: testAccDataSourceStorageObjectsLimit(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "names.#", "1"),
+					resource.TestCheckResourceAttr(resName, "names.0", "builds/1.1.0/app.tar.gz"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceStorageObjectsObjects(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_storage_container" "test" {
+  name = "acc-test-objects-%d"
+}
+
+resource "opc_storage_object" "readme" {
+  name      = "README"
+  container = "${opc_storage_container.test.name}"
+  content   = "readme"
+}
+
+resource "opc_storage_object" "v1" {
+  name         = "builds/1.0.0/app.tar.gz"
+  container    = "${opc_storage_container.test.name}"
+  content_type = "application/gzip"
+  content      = "v1.0.0"
+}
+
+resource "opc_storage_object" "v2" {
+  name         = "builds/1.1.0/app.tar.gz"
+  container    = "${opc_storage_container.test.name}"
+  content_type = "application/gzip"
+  content      = "v1.1.0"
+}`, rInt)
+}
+
+func testAccDataSourceStorageObjectsBasic(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "opc_storage_objects" "test" {
+  container = "${opc_storage_container.test.name}"
+  prefix    = "builds/"
+}
+
+data "opc_storage_objects" "prefixes" {
+  container = "${opc_storage_container.test.name}"
+  delimiter = "/"
+}`, testAccDataSourceStorageObjectsObjects(rInt))
+}
+
+func testAccDataSourceStorageObjectsLimit(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "opc_storage_objects" "test" {
+  container = "${opc_storage_container.test.name}"
+  prefix    = "builds/"
+  marker    = "builds/1.0.0/app.tar.gz"
+  limit     = 1
+}`, testAccDataSourceStorageObjectsObjects(rInt))
+}
@@ -0,0 +1,446 @@
+package opc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// Bodies larger than this are truncated in the trace
+	httpTraceMaxBody = 64 * 1024
+	// Replaces the values of credentials in the trace
+	httpTraceRedacted = "REDACTED"
+)
+
+// Headers which carry credentials, compared in canonical form
+var httpTraceSecretHeaders = map[string]bool{
+	"Authorization":                   true,
+	"Cookie":                          true,
+	"Set-Cookie":                      true,
+	"X-Auth-Key":                      true,
+	"X-Auth-Token":                    true,
+	"X-Storage-Pass":                  true,
+	"X-Storage-Token":                 true,
+	"X-Container-Meta-Temp-Url-Key":   true,
+	"X-Container-Meta-Temp-Url-Key-2": true,
+}
+
+// Fields of JSON bodies, and query parameters, which carry credentials or keys
+var httpTraceSecretFields = map[string]bool{
+	"password":       true,
+	"private_key":    true,
+	"pre_shared_key": true,
+	"psk":            true,
+	"temp_url_sig":   true,
+}
+
+// The key of the request context value holding the attempt number set by the retryTransport
+type retryAttemptKey struct{}
+
+// Returns the attempt number of the request, starting from 1
+func retryAttempt(req *http.Request) int {
+	if attempt, ok := req.Context().Value(retryAttemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+// Sets the attempt number on the request's context, for the traceTransport
+func withRetryAttempt(req *http.Request, attempt int) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), retryAttemptKey{}, attempt))
+}
+
+// Tracers are shared by every provider instance writing to the same file
+var (
+	httpTracersLock sync.Mutex
+	httpTracers     = make(map[string]*httpTracer)
+)
+
+// The HAR file is written as this header, the entries separated by commas, then the trailer
+const (
+	harHeader  = "{\n  \"log\": {\n    \"version\": \"1.2\",\n    \"creator\": {\"name\": \"terraform-provider-opc\", \"version\": \"1\"},\n    \"entries\": [\n"
+	harTrailer = "\n    ]\n  }\n}\n"
+)
+
+// httpTracer records requests and responses to a file, either as a HAR file, when the path
+// ends in .har, or as a JSON object per line. Each entry of a HAR file is written over the
+// trailer, which is written again after it, so the file is complete whenever the provider
+// stops without keeping the entries in memory.
+type httpTracer struct {
+	mu   sync.Mutex
+	path string
+	har  bool
+	// The number of entries in the HAR file, and the offset its trailer starts at
+	entries int
+	offset  int64
+}
+
+// Returns the tracer writing to the path, truncating the file the first time it's opened
+func openHTTPTracer(path string) (*httpTracer, error) {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error expanding homedir in http_trace (%s): %s", path, err)
+	}
+	if expanded, err = filepath.Abs(expanded); err != nil {
+		return nil, err
+	}
+
+	httpTracersLock.Lock()
+	defer httpTracersLock.Unlock()
+	if tracer, ok := httpTracers[expanded]; ok {
+		return tracer, nil
+	}
+	tracer := &httpTracer{
+		path: expanded,
+		har:  strings.EqualFold(filepath.Ext(expanded), ".har"),
+	}
+	var content []byte
+	if tracer.har {
+		content = []byte(harHeader + harTrailer)
+		tracer.offset = int64(len(harHeader))
+	}
+	if err := ioutil.WriteFile(expanded, content, 0600); err != nil {
+		return nil, fmt.Errorf("Error creating http_trace file: %s", err)
+	}
+	httpTracers[expanded] = tracer
+	return tracer, nil
+}
+
+func (t *httpTracer) record(entry *harEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var err error
+	if t.har {
+		err = t.writeHAREntry(entry)
+	} else {
+		err = t.appendLine(entry)
+	}
+	if err != nil {
+		log.Printf("[WARN] Error writing HTTP trace to %s: %s", t.path, err)
+	}
+}
+
+func (t *httpTracer) writeHAREntry(entry *harEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if t.entries > 0 {
+		data = append([]byte(",\n"), data...)
+	}
+	f, err := os.OpenFile(t.path, os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// The entry and the trailer are always longer than the trailer they're written over
+	if _, err := f.WriteAt(append(data, harTrailer...), t.offset); err != nil {
+		return err
+	}
+	t.entries++
+	t.offset += int64(len(data))
+	return nil
+}
+
+func (t *httpTracer) appendLine(entry *harEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// traceTransport records every request sent to a service, and its response, with a tracer.
+// It sits below the retryTransport and sessionTransport, so each attempt and authentication
+// request is recorded separately.
+type traceTransport struct {
+	tracer    *httpTracer
+	service   string
+	transport http.RoundTripper
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := &harEntry{
+		StartedDateTime: time.Now().Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         redactURL(req.URL),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     redactHeaders(req.Header),
+			QueryString: redactQuery(req.URL.Query()),
+			HeadersSize: -1,
+			BodySize:    req.ContentLength,
+		},
+		Timings: harTimings{Send: 0, Wait: -1, Receive: -1},
+		Service: t.service,
+		Attempt: retryAttempt(req),
+	}
+	entry.Request.PostData = traceRequestBody(req)
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	entry.Timings.Wait = milliseconds(time.Since(start))
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Time = entry.Timings.Wait
+		entry.Response = harResponse{Cookies: []harNameValue{}, Headers: []harNameValue{}, HeadersSize: -1, BodySize: -1}
+		t.tracer.record(entry)
+		return resp, err
+	}
+
+	received := time.Now()
+	entry.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     redactHeaders(resp.Header),
+		HeadersSize: -1,
+		BodySize:    resp.ContentLength,
+	}
+	entry.Response.Content = traceResponseBody(resp)
+	entry.Timings.Receive = milliseconds(time.Since(received))
+	entry.Time = entry.Timings.Wait + entry.Timings.Receive
+	t.tracer.record(entry)
+	return resp, nil
+}
+
+// Reads a copy of the request body, if it can be sent again. Streamed bodies, such as storage
+// object uploads, aren't read so the upload isn't buffered.
+func traceRequestBody(req *http.Request) *harPostData {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	contentType := req.Header.Get("Content-Type")
+	if req.GetBody == nil {
+		return &harPostData{MimeType: contentType, Comment: "streamed body not captured"}
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return &harPostData{MimeType: contentType, Comment: fmt.Sprintf("error reading body: %s", err)}
+	}
+	defer body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(body, httpTraceMaxBody+1))
+	if err != nil {
+		return &harPostData{MimeType: contentType, Comment: fmt.Sprintf("error reading body: %s", err)}
+	}
+	text, comment := traceBody(contentType, data)
+	return &harPostData{MimeType: contentType, Text: text, Comment: comment}
+}
+
+// Reads the start of the response body, leaving the whole body in place for the caller
+func traceResponseBody(resp *http.Response) harContent {
+	contentType := resp.Header.Get("Content-Type")
+	content := harContent{Size: resp.ContentLength, MimeType: contentType}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		content.Size = 0
+		return content
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, httpTraceMaxBody+1))
+	resp.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(data), resp.Body), Closer: resp.Body}
+	if err != nil {
+		content.Comment = fmt.Sprintf("error reading body: %s", err)
+		return content
+	}
+	if len(data) <= httpTraceMaxBody {
+		content.Size = int64(len(data))
+	}
+	content.Text, content.Comment = traceBody(contentType, data)
+	return content
+}
+
+// Returns the redacted body as text, or a comment on why it wasn't captured
+func traceBody(contentType string, data []byte) (string, string) {
+	if len(data) > httpTraceMaxBody {
+		return "", fmt.Sprintf("body larger than %d bytes not captured", httpTraceMaxBody)
+	}
+	if len(data) == 0 {
+		return "", ""
+	}
+	if !isTextContentType(contentType) {
+		return "", fmt.Sprintf("%s body not captured", contentType)
+	}
+	return redactBody(data), ""
+}
+
+func isTextContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, text := range []string{"json", "text/", "xml", "x-www-form-urlencoded"} {
+		if strings.Contains(contentType, text) {
+			return true
+		}
+	}
+	return contentType == ""
+}
+
+// Redacts the credential fields of a JSON body, or the whole body if it can't be parsed and
+// might contain a credential
+func redactBody(data []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		lower := strings.ToLower(string(data))
+		for field := range httpTraceSecretFields {
+			if strings.Contains(lower, field) {
+				return httpTraceRedacted
+			}
+		}
+		return string(data)
+	}
+	redacted, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return httpTraceRedacted
+	}
+	return string(redacted)
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if httpTraceSecretFields[strings.ToLower(key)] {
+				v[key] = httpTraceRedacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+func redactHeaders(header http.Header) []harNameValue {
+	values := []harNameValue{}
+	for name, vs := range header {
+		for _, value := range vs {
+			if httpTraceSecretHeaders[http.CanonicalHeaderKey(name)] {
+				value = httpTraceRedacted
+			}
+			values = append(values, harNameValue{Name: name, Value: value})
+		}
+	}
+	return values
+}
+
+func redactQuery(query url.Values) []harNameValue {
+	values := []harNameValue{}
+	for name, vs := range query {
+		for _, value := range vs {
+			if httpTraceSecretFields[strings.ToLower(name)] {
+				value = httpTraceRedacted
+			}
+			values = append(values, harNameValue{Name: name, Value: value})
+		}
+	}
+	return values
+}
+
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	query := u.Query()
+	for name := range query {
+		if httpTraceSecretFields[strings.ToLower(name)] {
+			query.Set(name, httpTraceRedacted)
+		}
+	}
+	if u.RawQuery != "" {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// The HAR 1.2 entries, see http://www.softwareishard.com/blog/har-12-spec/. The custom fields
+// starting with an underscore record the service, retry attempt and transport error.
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Service         string      `json:"_service"`
+	Attempt         int         `json:"_attempt"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,160 @@
+package opc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTraceTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.SetCookie(w, &http.Cookie{Name: "nimbula", Value: "session-secret"})
+		w.Header().Set("Content-Type", "application/oracle-compute-v3+json")
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name": "/Compute-domain/user/key", "private_key": "key-secret"}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "opc-http-trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"trace.jsonl", "trace.har"} {
+		requests = 0
+		tracer, err := openHTTPTracer(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		transport := &retryTransport{
+			policy:    testRetryPolicy(),
+			transport: &traceTransport{tracer: tracer, service: "Compute", transport: http.DefaultTransport},
+		}
+
+		body := []byte(`{"user": "/Compute-domain/user", "password": "password-secret"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/authenticate/?temp_url_sig=sig-secret", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/oracle-compute-v3+json")
+		req.Header.Set("X-Auth-Token", "token-secret")
+		req.Header.Set("X-Container-Meta-Temp-Url-Key", "temp-url-key-secret")
+		req.Header.Set("X-Container-Meta-Temp-Url-Key-2", "temp-url-key-2-secret")
+		req.SetBasicAuth("user", "basic-secret")
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The caller still receives the whole response body
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(respBody), "key-secret") {
+			t.Fatalf("Expected the response body to be left in place, got %q", respBody)
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret") {
+			t.Fatalf("Expected the credentials to be redacted from %s, got %s", name, data)
+		}
+
+		entries := testReadTraceEntries(t, name, data)
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 entries in %s, got %d", name, len(entries))
+		}
+		for i, entry := range entries {
+			if entry.Attempt != i+1 || entry.Service != "Compute" || entry.Request.Method != "POST" {
+				t.Fatalf("Expected attempt %d of the Compute POST, got %#v", i+1, entry)
+			}
+		}
+		if entries[0].Response.Status != http.StatusServiceUnavailable || entries[1].Response.Status != http.StatusOK {
+			t.Fatalf("Expected a 503 then a 200, got %d and %d", entries[0].Response.Status, entries[1].Response.Status)
+		}
+		if !strings.Contains(entries[1].Request.PostData.Text, `"user":"/Compute-domain/user"`) ||
+			!strings.Contains(entries[1].Response.Content.Text, `"name":"/Compute-domain/user/key"`) {
+			t.Fatalf("Expected the bodies to be recorded, got %#v", entries[1])
+		}
+	}
+}
+
+func TestHTTPTracer_emptyHAR(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opc-http-trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The HAR file is complete before any requests are made
+	path := filepath.Join(dir, "empty.har")
+	if _, err := openHTTPTracer(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := testReadTraceEntries(t, "empty.har", data); len(entries) != 0 {
+		t.Fatalf("Expected no entries, got %d", len(entries))
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := map[string]string{
+		`{"psk": "secret", "settings": [{"Pre_Shared_Key": "secret"}]}`: `{"psk":"REDACTED","settings":[{"Pre_Shared_Key":"REDACTED"}]}`,
+		`{"name": "certificate"}`: `{"name":"certificate"}`,
+		`password=secret`:         `REDACTED`,
+		`plain text`:              `plain text`,
+	}
+
+	for body, expected := range cases {
+		if redacted := redactBody([]byte(body)); redacted != expected {
+			t.Errorf("Expected %q to be redacted as %q, got %q", body, expected, redacted)
+		}
+	}
+}
+
+// The HAR 1.2 log the tracer writes, see http://www.softwareishard.com/blog/har-12-spec/
+type harLog struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func testReadTraceEntries(t *testing.T, name string, data []byte) []*harEntry {
+	var entries []*harEntry
+	if filepath.Ext(name) == ".har" {
+		var har struct {
+			Log harLog `json:"log"`
+		}
+		if err := json.Unmarshal(data, &har); err != nil {
+			t.Fatalf("Error parsing %s: %s", name, err)
+		}
+		return har.Log.Entries
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		entry := &harEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			t.Fatalf("Error parsing %s: %s", name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
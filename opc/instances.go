@@ -53,6 +53,8 @@ func orchestrationInstanceSchema() *schema.Schema {
 					DiffSuppressFunc: structure.SuppressJsonDiff,
 				},
 
+				"user_data": userDataSchema(false),
+
 				"boot_order": {
 					Type:     schema.TypeList,
 					Optional: true,
@@ -398,14 +400,19 @@ func expandCreateInstanceInput(prefix string, d *schema.ResourceData) (*compute.
 		input.Tags = tags
 	}
 
+	var attrs map[string]interface{}
 	if attributes, ok := d.GetOk(fmt.Sprintf("%s.instance_attributes", prefix)); ok {
-		attrs, err := structure.ExpandJsonFromString(attributes.(string))
+		attrs, err = structure.ExpandJsonFromString(attributes.(string))
 		if err != nil {
 			return nil, fmt.Errorf("Cannot parse attributes as json: %s", err)
 		}
-		if attrs != nil {
-			input.Attributes = attrs
-		}
+	}
+	attrs, err = mergeUserData(attrs, d.Get(fmt.Sprintf("%s.user_data", prefix)).([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	if attrs != nil {
+		input.Attributes = attrs
 	}
 
 	return input, nil
@@ -474,7 +481,6 @@ func flattenOrchestratedInstances(d *schema.ResourceData, meta interface{}, obje
 
 	result := make([]interface{}, len(objects))
 	for i := range objects {
-		v := make(map[string]interface{})
 		getIDInput := &compute.GetInstanceIDInput{
 			// Oracle's api returns an unordered list so we'll find out instances through the config file name
 			Name: d.Get(fmt.Sprintf("instance.%d.name", i)).(string),
@@ -484,84 +490,101 @@ func flattenOrchestratedInstances(d *schema.ResourceData, meta interface{}, obje
 			return nil, err
 		}
 
-		v["name"] = instance.Name
+		// The attributes are compared with instance_attributes, so they're read without user_data
+		userData := d.Get(fmt.Sprintf("instance.%d.user_data", i)).([]interface{})
+		removeUserData(instance.Attributes, userData)
+
+		v, err := flattenInstance(instance)
+		if err != nil {
+			return nil, err
+		}
 		v["persistent"] = objects[i].Persistent
-		v["shape"] = instance.Shape
-		v["id"] = instance.ID
 
 		if attrs, ok := d.GetOk(fmt.Sprintf("instance.%d.instance_attributes", i)); ok && attrs != nil {
 			v["instance_attributes"] = attrs.(string)
-		} else {
-			instanceAttributes, err := flattenInstanceAttributes(instance.Attributes)
-			if err != nil {
-				return nil, err
-			}
-			v["instance_attributes"] = instanceAttributes
 		}
+		v["user_data"] = userData
 
-		sort.Ints(instance.BootOrder)
-		v["boot_order"] = instance.BootOrder
+		result[i] = v
+	}
 
-		splitHostname := strings.Split(instance.Hostname, ".")
-		if len(splitHostname) == 0 {
-			return nil, fmt.Errorf("Unable to parse hostname: %s", instance.Hostname)
-		}
-		v["hostname"] = splitHostname[0]
-		v["fqdn"] = instance.Hostname
+	return result, nil
+}
 
-		v["image_list"] = instance.ImageList
-		v["label"] = instance.Label
+// Flattens the computed attributes of an instance, as read by the instances client
+func flattenInstance(instance *compute.InstanceInfo) (map[string]interface{}, error) {
+	v := make(map[string]interface{})
 
-		networkInterfaces, err := flattenNetworkInterfaces(instance.Networking)
-		if err != nil {
-			return nil, err
-		}
-		if len(networkInterfaces) > 0 {
-			v["networking_info"] = networkInterfaces
-		}
+	v["name"] = instance.Name
+	v["shape"] = instance.Shape
+	v["id"] = instance.ID
+
+	instanceAttributes, err := flattenInstanceAttributes(instance.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	v["instance_attributes"] = instanceAttributes
+
+	sort.Ints(instance.BootOrder)
+	v["boot_order"] = instance.BootOrder
+
+	splitHostname := strings.Split(instance.Hostname, ".")
+	if len(splitHostname) == 0 {
+		return nil, fmt.Errorf("Unable to parse hostname: %s", instance.Hostname)
+	}
+	v["hostname"] = splitHostname[0]
+	v["fqdn"] = instance.Hostname
 
-		sort.Strings(instance.SSHKeys)
-		v["ssh_keys"] = instance.SSHKeys
+	v["image_list"] = instance.ImageList
+	v["label"] = instance.Label
 
-		v["reverse_dns"] = instance.ReverseDNS
+	networkInterfaces, err := flattenNetworkInterfaces(instance.Networking)
+	if err != nil {
+		return nil, err
+	}
+	if len(networkInterfaces) > 0 {
+		v["networking_info"] = networkInterfaces
+	}
 
-		v["storage"] = flattenInstanceStorageAttachments(instance.Storage)
+	sort.Strings(instance.SSHKeys)
+	v["ssh_keys"] = instance.SSHKeys
 
-		sort.Strings(instance.Tags)
-		v["tags"] = instance.Tags
+	v["reverse_dns"] = instance.ReverseDNS
 
-		v["availability_domain"] = instance.AvailabilityDomain
-		v["domain"] = instance.Domain
-		v["entry"] = instance.Entry
-		v["fingerprint"] = instance.Fingerprint
-		v["image_format"] = instance.ImageFormat
-		v["ip_address"] = instance.IPAddress
+	v["storage"] = flattenInstanceStorageAttachments(instance.Storage)
 
-		sort.Strings(instance.PlacementRequirements)
-		v["placement_requirements"] = instance.PlacementRequirements
+	sort.Strings(instance.Tags)
+	v["tags"] = instance.Tags
 
-		v["platform"] = instance.Platform
-		v["priority"] = instance.Priority
-		v["quota_reservation"] = instance.QuotaReservation
+	v["availability_domain"] = instance.AvailabilityDomain
+	v["domain"] = instance.Domain
+	v["entry"] = instance.Entry
+	v["fingerprint"] = instance.Fingerprint
+	v["image_format"] = instance.ImageFormat
+	v["ip_address"] = instance.IPAddress
 
-		sort.Strings(instance.Relationships)
-		v["relationships"] = instance.Relationships
+	sort.Strings(instance.PlacementRequirements)
+	v["placement_requirements"] = instance.PlacementRequirements
 
-		sort.Strings(instance.Resolvers)
-		v["resolvers"] = instance.Resolvers
+	v["platform"] = instance.Platform
+	v["priority"] = instance.Priority
+	v["quota_reservation"] = instance.QuotaReservation
 
-		v["site"] = instance.Site
-		v["start_time"] = instance.StartTime
-		v["state"] = instance.State
+	sort.Strings(instance.Relationships)
+	v["relationships"] = instance.Relationships
 
-		v["vcable"] = instance.VCableID
-		v["virtio"] = instance.Virtio
-		v["vnc_address"] = instance.VNC
+	sort.Strings(instance.Resolvers)
+	v["resolvers"] = instance.Resolvers
 
-		result[i] = v
-	}
+	v["site"] = instance.Site
+	v["start_time"] = instance.StartTime
+	v["state"] = instance.State
 
-	return result, nil
+	v["vcable"] = instance.VCableID
+	v["virtio"] = instance.Virtio
+	v["vnc_address"] = instance.VNC
+
+	return v, nil
 }
 
 // Flattens attributes from the returned instance object, and sets the computed attributes string
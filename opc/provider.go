@@ -1,35 +1,54 @@
 package opc
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 )
 
 // Provider returns the provider schema
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"user": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPC_USERNAME", nil),
 				Description: "The user name for OPC API operations.",
 			},
 
 			"password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPC_PASSWORD", nil),
 				Description: "The user password for OPC API operations.",
 			},
 
 			"identity_domain": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPC_IDENTITY_DOMAIN", nil),
 				Description: "The OPC identity domain for API operations",
 			},
 
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_PROFILE", nil),
+				Description: "The profile in the credentials file to read settings from (defaults to default)",
+			},
+
+			"config_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_CONFIG_FILE", nil),
+				Description: "The path of the credentials file (defaults to ~/.opc/config)",
+			},
+
 			"endpoint": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -41,7 +60,54 @@ func Provider() terraform.ResourceProvider {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPC_MAX_RETRIES", 1),
-				Description: "Maximum number retries to wait for a successful response when operating on resources within OPC (defaults to 1)",
+				Description: "Number of times to retry a request after its first attempt when operating on resources within OPC, and to launch again an instance which fails to start. Only transient errors are retried (defaults to 1)",
+			},
+
+			"retryable_status_codes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt, ValidateFunc: validation.IntBetween(400, 599)},
+				Description: "HTTP status codes that are retried, up to `max_retries` times (defaults to 429, 500, 502, 503 and 504)",
+			},
+
+			"retry_max_elapsed_time": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OPC_RETRY_MAX_ELAPSED_TIME", "5m"),
+				ValidateFunc: validateDuration,
+				Description:  "Maximum time to spend retrying a single request (defaults to 5m)",
+			},
+
+			"retry_base_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OPC_RETRY_BASE_BACKOFF", "1s"),
+				ValidateFunc: validateDuration,
+				Description:  "Wait before the first retry, doubled for each subsequent retry (defaults to 1s)",
+			},
+
+			"retry_max_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OPC_RETRY_MAX_BACKOFF", "60s"),
+				ValidateFunc: validateDuration,
+				Description:  "Maximum wait between retries (defaults to 60s)",
+			},
+
+			"poll_interval": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OPC_POLL_INTERVAL", nil),
+				ValidateFunc: validatePollInterval,
+				Description:  "Wait between checks of a resource's state while waiting for it to change, at least 1s (defaults to the API clients' intervals)",
+			},
+
+			"poll_max_interval": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OPC_POLL_MAX_INTERVAL", "30s"),
+				ValidateFunc: validatePollInterval,
+				Description:  "Maximum wait between the provider's own checks, as it doubles the wait after each check; the API clients always wait poll_interval (defaults to 30s)",
 			},
 
 			"insecure": {
@@ -51,6 +117,13 @@ func Provider() terraform.ResourceProvider {
 				Description: "Skip TLS Verification for self-signed certificates. Should only be used if absolutely required.",
 			},
 
+			"http_trace": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_HTTP_TRACE", nil),
+				Description: "Records every API request and response, with credentials redacted, to this file. Written as HAR if the file ends in .har, otherwise as JSON lines",
+			},
+
 			"storage_endpoint": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -74,14 +147,21 @@ func Provider() terraform.ResourceProvider {
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
+			"opc_compute_image_list":              dataSourceImageList(),
 			"opc_compute_image_list_entry":        dataSourceImageListEntry(),
+			"opc_compute_instance_console":        dataSourceInstanceConsole(),
+			"opc_compute_instances":               dataSourceInstances(),
 			"opc_compute_ip_address_reservation":  dataSourceIPAddressReservation(),
 			"opc_compute_ip_reservation":          dataSourceIPReservation(),
 			"opc_compute_machine_image":           dataSourceMachineImage(),
 			"opc_compute_network_interface":       dataSourceNetworkInterface(),
+			"opc_compute_shapes":                  dataSourceShapes(),
 			"opc_compute_ssh_key":                 dataSourceSSHKey(),
 			"opc_compute_storage_volume_snapshot": dataSourceStorageVolumeSnapshot(),
 			"opc_compute_vnic":                    dataSourceVNIC(),
+			"opc_storage_object":                  dataSourceStorageObject(),
+			"opc_storage_object_temp_url":         dataSourceStorageObjectTempURL(),
+			"opc_storage_objects":                 dataSourceStorageObjects(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -119,15 +199,52 @@ func Provider() terraform.ResourceProvider {
 			"opc_lbaas_policy":                    resourceLBaaSPolicy(),
 			"opc_lbaas_server_pool":               resourceLBaaSOriginServerPool(),
 			"opc_storage_container":               resourceOPCStorageContainer(),
+			"opc_storage_directory":               resourceOPCStorageDirectory(),
 			"opc_storage_object":                  resourceOPCStorageObject(),
+			"opc_storage_object_restore":          resourceOPCStorageObjectRestore(),
 		},
+	}
+	// Interrupting Terraform cancels the requests made by the clients, and any waits
+	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return providerConfigure(d, provider.StopContext())
+	}
 
-		ConfigureFunc: providerConfigure,
+	// The TLS and proxy settings apply to every service, unless overridden in the service's block
+	for key, value := range transportSchema(true) {
+		provider.Schema[key] = value
 	}
+	for key, service := range transportServices {
+		provider.Schema[key] = &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Elem:        &schema.Resource{Schema: transportSchema(false)},
+			Description: fmt.Sprintf("TLS and proxy settings for the %s API, overriding the provider's", service),
+		}
+	}
+
+	return provider
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	config := Config{
+// The blocks overriding the transport settings for each service
+var transportServices = map[string]string{
+	"compute_transport": "Compute Classic",
+	"storage_transport": "Object Storage Classic",
+	"lbaas_transport":   "Load Balancer Classic",
+}
+
+func providerConfigure(d *schema.ResourceData, stop context.Context) (interface{}, error) {
+	config, err := providerConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	config.StopContext = stop
+	return config.Client()
+}
+
+// Settings in the provider block, or their environment variables, take precedence over the profile
+func providerConfig(d *schema.ResourceData) (*Config, error) {
+	config := &Config{
 		User:             d.Get("user").(string),
 		Password:         d.Get("password").(string),
 		IdentityDomain:   d.Get("identity_domain").(string),
@@ -137,7 +254,62 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		StorageEndpoint:  d.Get("storage_endpoint").(string),
 		StorageServiceID: d.Get("storage_service_id").(string),
 		LBaaSEndpoint:    d.Get("lbaas_endpoint").(string),
+		HTTPTrace:        d.Get("http_trace").(string),
 	}
 
-	return config.Client()
+	for _, code := range d.Get("retryable_status_codes").([]interface{}) {
+		config.RetryableStatusCodes = append(config.RetryableStatusCodes, code.(int))
+	}
+	// Durations have already been checked by validateDuration
+	config.RetryMaxElapsedTime, _ = time.ParseDuration(d.Get("retry_max_elapsed_time").(string))
+	config.RetryBaseBackoff, _ = time.ParseDuration(d.Get("retry_base_backoff").(string))
+	config.RetryMaxBackoff, _ = time.ParseDuration(d.Get("retry_max_backoff").(string))
+	if v, ok := d.GetOk("poll_interval"); ok {
+		config.PollInterval, _ = time.ParseDuration(v.(string))
+	}
+	config.PollMaxInterval, _ = time.ParseDuration(d.Get("poll_max_interval").(string))
+
+	config.Transport = readTransportConfig(d.Get)
+	for key, override := range map[string]*transportConfig{
+		"compute_transport": &config.ComputeTransport,
+		"storage_transport": &config.StorageTransport,
+		"lbaas_transport":   &config.LBaaSTransport,
+	} {
+		if v, ok := d.GetOk(key); ok {
+			block := v.([]interface{})[0].(map[string]interface{})
+			*override = readTransportConfig(func(key string) interface{} { return block[key] })
+		}
+	}
+
+	path, explicitPath := d.GetOk("config_file")
+	if !explicitPath {
+		path = defaultCredentialsFile
+	}
+	name, explicitProfile := d.GetOk("profile")
+	if !explicitProfile {
+		name = defaultProfile
+	}
+	profile, err := loadCredentialsProfile(path.(string), name.(string), explicitPath || explicitProfile)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.applyProfile(profile); err != nil {
+		return nil, err
+	}
+
+	required := []struct {
+		key   string
+		value string
+	}{
+		{"user", config.User},
+		{"password", config.Password},
+		{"identity_domain", config.IdentityDomain},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return nil, fmt.Errorf("%s must be set in the provider, its environment variable or the credentials file profile", r.key)
+		}
+	}
+
+	return config, nil
 }
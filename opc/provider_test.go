@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-opc/opc/simulator"
 )
 
 var testAccProviders map[string]terraform.ResourceProvider
@@ -21,6 +22,27 @@ func init() {
 	}
 }
 
+// When OPC_SIMULATOR is set the acceptance tests are run against an in-memory simulator
+// of the OPC APIs rather than a real account
+func TestMain(m *testing.M) {
+	if os.Getenv("OPC_SIMULATOR") == "" {
+		os.Exit(m.Run())
+	}
+
+	sim := simulator.New()
+	os.Setenv("OPC_USERNAME", sim.User)
+	os.Setenv("OPC_PASSWORD", sim.Password)
+	os.Setenv("OPC_IDENTITY_DOMAIN", sim.IdentityDomain)
+	os.Setenv("OPC_ENDPOINT", sim.ComputeEndpoint())
+	os.Setenv("OPC_STORAGE_ENDPOINT", sim.StorageEndpoint())
+	os.Setenv("OPC_LBAAS_ENDPOINT", sim.LBaaSEndpoint())
+	os.Unsetenv("OPC_STORAGE_SERVICE_ID")
+
+	code := m.Run()
+	sim.Close()
+	os.Exit(code)
+}
+
 func TestProvider(t *testing.T) {
 	if err := Provider().(*schema.Provider).InternalValidate(); err != nil {
 		t.Fatalf("Error creating Provider: %s", err)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +17,9 @@ import (
 	"github.com/hashicorp/terraform/helper/validation"
 )
 
+// How long each attempt to connect to the port in wait_for waits for an answer
+const instanceReadyDialTimeout = 5 * time.Second
+
 func resourceInstance() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceInstanceCreate,
@@ -29,7 +33,24 @@ func resourceInstance() *schema.Resource {
 					return nil, fmt.Errorf("Invalid ID specified. Must be in the form of instance_name/instance_id. Got: %s", d.Id())
 				}
 				d.Set("name", strings.Join(combined[0:len(combined)-1], "/"))
+				d.Set("allow_stop_for_update", false)
 				d.SetId(combined[len(combined)-1])
+
+				// Every storage attachment of an imported instance is managed by it
+				computeClient, err := meta.(*Client).getComputeClient()
+				if err != nil {
+					return nil, err
+				}
+				instance, err := computeClient.Instances().GetInstance(&compute.GetInstanceInput{
+					Name: d.Get("name").(string),
+					ID:   d.Id(),
+				})
+				if err != nil {
+					return nil, fmt.Errorf("Error reading instance %s: %s", d.Id(), err)
+				}
+				if err := setStorageAttachments(d, instance.Storage); err != nil {
+					return nil, err
+				}
 				return []*schema.ResourceData{d}, nil
 			},
 		},
@@ -40,6 +61,8 @@ func resourceInstance() *schema.Resource {
 			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
+		CustomizeDiff: resourceInstanceCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			/////////////////////////
 			// Required Attributes //
@@ -50,10 +73,10 @@ func resourceInstance() *schema.Resource {
 				ForceNew: true,
 			},
 
+			// Replaces the instance, unless allow_stop_for_update is set
 			"shape": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			/////////////////////////
@@ -66,6 +89,8 @@ func resourceInstance() *schema.Resource {
 				ValidateFunc: validation.ValidateJsonString,
 			},
 
+			"user_data": userDataSchema(true),
+
 			"boot_order": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -99,6 +124,12 @@ func resourceInstance() *schema.Resource {
 				Default:  compute.InstanceDesiredRunning,
 			},
 
+			"allow_stop_for_update": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"networking_info": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -243,19 +274,16 @@ func resourceInstance() *schema.Resource {
 					}
 					return false
 				},
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"index": {
 							Type:         schema.TypeInt,
 							Required:     true,
-							ForceNew:     true,
 							ValidateFunc: validation.IntBetween(1, 10),
 						},
 						"volume": {
 							Type:     schema.TypeString,
 							Required: true,
-							ForceNew: true,
 						},
 						"name": {
 							Type:     schema.TypeString,
@@ -265,6 +293,35 @@ func resourceInstance() *schema.Resource {
 				},
 			},
 
+			"wait_for": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"port": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(1, 65535),
+						},
+						"address": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"console_marker": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+
+			"reboot_trigger": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
 			"tags": tagsForceNewSchema(),
 
 			/////////////////////////
@@ -376,12 +433,98 @@ func resourceInstance() *schema.Resource {
 	}
 }
 
+func resourceInstanceCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeInstanceShapeDiff(diff, meta); err != nil {
+		return err
+	}
+	if err := customizeInstanceStorageDiff(diff); err != nil {
+		return err
+	}
+	if err := customizeInstanceWaitForDiff(diff); err != nil {
+		return err
+	}
+	if diff.NewValueKnown("instance_attributes") {
+		return checkUserData(diff.Get("instance_attributes").(string), diff.Get("user_data").([]interface{}))
+	}
+	return nil
+}
+
+func customizeInstanceShapeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.HasChange("shape") {
+		return nil
+	}
+	if diff.NewValueKnown("shape") {
+		if err := validateShapeAvailable(meta, diff.Get("shape").(string)); err != nil {
+			return err
+		}
+	}
+	if diff.Id() == "" || diff.HasChange("boot_order") {
+		return nil
+	}
+
+	// Changing the shape stops the instance, so it's only done when allowed
+	if !diff.Get("allow_stop_for_update").(bool) {
+		return diff.ForceNew("shape")
+	}
+	// The disk of an instance booted from an image list doesn't survive it being stopped
+	if len(diff.Get("boot_order").([]interface{})) == 0 {
+		return fmt.Errorf("The shape of instance %s can't be changed without replacing it, as it doesn't boot from a persistent storage volume. "+
+			"Set boot_order to boot from a storage volume, or remove allow_stop_for_update to replace the instance", diff.Get("name").(string))
+	}
+	return nil
+}
+
+// A wait_for block must say what to wait for
+func customizeInstanceWaitForDiff(diff *schema.ResourceDiff) error {
+	waitFor := diff.Get("wait_for").([]interface{})
+	if len(waitFor) == 0 || waitFor[0] == nil {
+		return nil
+	}
+	config := waitFor[0].(map[string]interface{})
+	if config["address"].(string) != "" && config["port"].(int) == 0 {
+		return fmt.Errorf("wait_for.0.address can only be set along with wait_for.0.port")
+	}
+	if config["port"].(int) == 0 && config["console_marker"].(string) == "" {
+		return fmt.Errorf("One of wait_for.0.port or wait_for.0.console_marker must be set")
+	}
+	return nil
+}
+
+// Storage volumes are attached and detached in place, other than the volume the instance boots from
+func customizeInstanceStorageDiff(diff *schema.ResourceDiff) error {
+	if diff.Id() == "" || !diff.HasChange("storage") || diff.HasChange("boot_order") {
+		return nil
+	}
+	o, n := diff.GetChange("storage")
+	return checkStorageBootIndexes(diff.Get("name").(string), diff.Get("boot_order").([]interface{}), o.(*schema.Set), n.(*schema.Set))
+}
+
+// Returns an error if the storage attached at one of the boot indexes differs between the sets
+func checkStorageBootIndexes(name string, bootOrder []interface{}, old, new *schema.Set) error {
+	changed := append(old.Difference(new).List(), new.Difference(old).List()...)
+	for _, boot := range bootOrder {
+		for _, v := range changed {
+			attachment := v.(map[string]interface{})
+			if attachment["index"].(int) == boot.(int) {
+				return fmt.Errorf("The storage at index %d of instance %s can't be changed without replacing it, as the instance boots from it. "+
+					"Taint the instance to replace it", boot, name)
+			}
+		}
+	}
+	return nil
+}
+
 func resourceInstanceCreate(d *schema.ResourceData, meta interface{}) error {
+	start := time.Now()
 	computeClient, err := meta.(*Client).getComputeClient()
 	if err != nil {
 		return err
 	}
 	resClient := computeClient.Instances()
+	instanceActions, err := meta.(*Client).getInstanceActionsClient()
+	if err != nil {
+		return err
+	}
 
 	// Get Required Attributes
 	input := &compute.CreateInstanceInput{
@@ -441,16 +584,152 @@ func resourceInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 		input.Tags = tags
 	}
 
-	result, err := resClient.CreateInstance(input)
-	if err != nil {
-		return fmt.Errorf("Error creating instance %s: %s", input.Name, err)
+	// An instance which fails to start is deleted and launched again, up to max_retries times
+	client := meta.(*Client)
+	for attempt := 0; ; attempt++ {
+		launched, err := instanceActions.LaunchInstance(input)
+		if err != nil {
+			return fmt.Errorf("Error creating instance %s: %s", input.Name, err)
+		}
+
+		log.Printf("[DEBUG] Launched instance %s: %#v", input.Name, launched.ID)
+
+		// The instance is recorded before waiting for it to run, so it's tainted rather than orphaned
+		// if the wait fails or Terraform is interrupted
+		d.SetId(launched.ID)
+
+		err = waitForInstanceRunning(client, resClient, input.Name, launched.ID, d.Timeout(schema.TimeoutCreate)-time.Since(start))
+		if err == nil {
+			break
+		}
+		if _, ok := err.(*instanceStartError); !ok || attempt >= client.maxRetries {
+			return fmt.Errorf("Error creating instance %s: %s", input.Name, err)
+		}
+
+		log.Printf("[WARN] Instance %s failed to start (attempt %d of %d), launching it again: %s", input.Name, attempt+1, client.maxRetries+1, err)
+		deleteInput := &compute.DeleteInstanceInput{
+			Name:         input.Name,
+			ID:           launched.ID,
+			Timeout:      d.Timeout(schema.TimeoutCreate) - time.Since(start),
+			PollInterval: client.pollInterval,
+		}
+		err = client.interruptible(fmt.Sprintf("instance %s to be deleted", input.Name), func() error {
+			return resClient.DeleteInstance(deleteInput)
+		})
+		if err != nil {
+			return fmt.Errorf("Error deleting instance %s which failed to start: %s", input.Name, err)
+		}
+		d.SetId("")
+	}
+
+	if err := resourceInstanceRead(d, meta); err != nil {
+		return err
 	}
 
-	log.Printf("[DEBUG] Created instance %s: %#v", input.Name, result.ID)
+	// The instance is tainted if the guest doesn't become ready within what's left of the create timeout
+	return waitForInstanceReady(d, meta, d.Timeout(schema.TimeoutCreate)-time.Since(start))
+}
 
-	d.SetId(result.ID)
+// instanceStartError is returned when a launched instance fails to start, rather than the wait failing
+type instanceStartError struct {
+	reason string
+}
 
-	return resourceInstanceRead(d, meta)
+func (e *instanceStartError) Error() string {
+	return fmt.Sprintf("Error initializing instance: %s", e.reason)
+}
+
+// Waits for a launched instance to be running, returning an instanceStartError if it fails to start
+func waitForInstanceRunning(client *Client, resClient *compute.InstancesClient, name, id string, timeout time.Duration) error {
+	return client.waitFor(fmt.Sprintf("instance %s to be running", name), timeout, func() (bool, error) {
+		info, err := resClient.GetInstance(&compute.GetInstanceInput{
+			Name: name,
+			ID:   id,
+		})
+		if err != nil {
+			return false, err
+		}
+		switch info.State {
+		case compute.InstanceError:
+			return false, &instanceStartError{reason: info.ErrorReason}
+		case compute.InstanceRunning:
+			return true, nil
+		}
+		log.Printf("[DEBUG] Instance %s is %s", name, info.State)
+		return false, nil
+	})
+}
+
+// Waits for the instance's guest to be ready, as configured by wait_for: first for the port to accept
+// TCP connections, then for the marker to appear in the console output
+func waitForInstanceReady(d *schema.ResourceData, meta interface{}, timeout time.Duration) error {
+	waitFor := d.Get("wait_for").([]interface{})
+	if len(waitFor) == 0 || waitFor[0] == nil {
+		return nil
+	}
+	config := waitFor[0].(map[string]interface{})
+	client := meta.(*Client)
+	name := d.Get("name").(string)
+	start := time.Now()
+
+	if port := config["port"].(int); port != 0 {
+		address, err := instanceReadyAddress(d)
+		if err != nil {
+			return err
+		}
+		target := net.JoinHostPort(address, strconv.Itoa(port))
+		err = client.waitFor(fmt.Sprintf("port %s of instance %s to accept connections", target, name), timeout, func() (bool, error) {
+			conn, err := net.DialTimeout("tcp", target, instanceReadyDialTimeout)
+			if err != nil {
+				log.Printf("[DEBUG] Port %s of instance %s isn't accepting connections yet: %s", target, name, err)
+				return false, nil
+			}
+			conn.Close()
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if marker := config["console_marker"].(string); marker != "" {
+		instanceActions, err := client.getInstanceActionsClient()
+		if err != nil {
+			return err
+		}
+		return client.waitFor(fmt.Sprintf("%q in the console of instance %s", marker, name), timeout-time.Since(start), func() (bool, error) {
+			console, err := instanceActions.GetInstanceConsole(name, d.Id())
+			if err != nil {
+				return false, fmt.Errorf("Error reading the console of instance %s: %s", name, err)
+			}
+			return strings.Contains(console.Output, marker), nil
+		})
+	}
+	return nil
+}
+
+// Returns the address to check the wait_for port on: the address set in the wait_for block, the IP address
+// of the instance on the shared network, or the IP address of its first interface which has one
+func instanceReadyAddress(d *schema.ResourceData) (string, error) {
+	if v := d.Get("wait_for.0.address").(string); v != "" {
+		return v, nil
+	}
+	if v := d.Get("ip_address").(string); v != "" {
+		return v, nil
+	}
+
+	address, firstIndex := "", -1
+	for _, v := range d.Get("networking_info").(*schema.Set).List() {
+		ni := v.(map[string]interface{})
+		index := ni["index"].(int)
+		if ip := ni["ip_address"].(string); ip != "" && (firstIndex == -1 || index < firstIndex) {
+			address, firstIndex = ip, index
+		}
+	}
+	if address == "" {
+		return "", fmt.Errorf("Instance %s has no IP address to wait for port %d on, set wait_for.0.address", d.Get("name").(string), d.Get("wait_for.0.port").(int))
+	}
+	return address, nil
 }
 
 func resourceInstanceRead(d *schema.ResourceData, meta interface{}) error {
@@ -580,13 +859,27 @@ func resourceInstanceUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	name := d.Get("name").(string)
 
+	if d.HasChange("shape") {
+		if err := updateInstanceShape(d, meta); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("storage") {
+		if err := updateInstanceStorage(d, meta); err != nil {
+			return err
+		}
+	}
+
 	input := &compute.UpdateInstanceInput{
-		Name:    name,
-		ID:      d.Id(),
-		Timeout: d.Timeout(schema.TimeoutUpdate),
+		Name:         name,
+		ID:           d.Id(),
+		Timeout:      d.Timeout(schema.TimeoutUpdate),
+		PollInterval: meta.(*Client).pollInterval,
 	}
 
-	if d.HasChange("desired_state") {
+	// Changing the shape leaves the instance shut down
+	if d.HasChange("desired_state") || d.HasChange("shape") {
 		input.DesiredState = compute.InstanceDesiredState(d.Get("desired_state").(string))
 	}
 
@@ -596,16 +889,192 @@ func resourceInstanceUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	}
 
-	result, err := resClient.UpdateInstance(input)
+	var result *compute.InstanceInfo
+	err = meta.(*Client).interruptible(fmt.Sprintf("instance %s to be updated", input.Name), func() (err error) {
+		result, err = resClient.UpdateInstance(input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating instance %s: %s", input.Name, err)
 	}
 
 	log.Printf("[DEBUG] Updated instance %s: %#v", result.Name, result.ID)
 
+	// Changing the shape already restarts the instance, and a shut down instance can't be rebooted
+	desiredState := compute.InstanceDesiredState(d.Get("desired_state").(string))
+	if d.HasChange("reboot_trigger") && !d.HasChange("shape") && desiredState != compute.InstanceDesiredShutdown {
+		if err := rebootInstance(d, meta); err != nil {
+			return err
+		}
+	}
+
 	return resourceInstanceRead(d, meta)
 }
 
+// Reboots the instance, waiting for the reboot request to complete
+func rebootInstance(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	instanceActions, err := client.getInstanceActionsClient()
+	if err != nil {
+		return err
+	}
+
+	name := d.Get("name").(string)
+	log.Printf("[DEBUG] Rebooting instance %s", name)
+	request, err := instanceActions.RebootInstance(name, d.Id(), false)
+	if err != nil {
+		return fmt.Errorf("Error rebooting instance %s: %s", name, err)
+	}
+
+	return client.waitFor(fmt.Sprintf("instance %s to reboot", name), d.Timeout(schema.TimeoutUpdate), func() (bool, error) {
+		result, err := instanceActions.GetRebootInstanceRequest(request.Name)
+		if err != nil {
+			return false, fmt.Errorf("Error reading the reboot request of instance %s: %s", name, err)
+		}
+		switch result.RequestState {
+		case rebootRequestComplete:
+			return true, nil
+		case rebootRequestError:
+			return false, fmt.Errorf("Error rebooting instance %s: %s", name, result.ErrorReason)
+		}
+		return false, nil
+	})
+}
+
+// Shuts the instance down, if it's running, and changes its shape. The instance is started again
+// by the rest of the update, or here if changing the shape fails.
+func updateInstanceShape(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	computeClient, err := client.getComputeClient()
+	if err != nil {
+		return err
+	}
+	instanceActions, err := client.getInstanceActionsClient()
+	if err != nil {
+		return err
+	}
+	resClient := computeClient.Instances()
+
+	name := d.Get("name").(string)
+	oldShape, newShape := d.GetChange("shape")
+	oldState, _ := d.GetChange("desired_state")
+
+	if compute.InstanceDesiredState(oldState.(string)) != compute.InstanceDesiredShutdown {
+		log.Printf("[DEBUG] Shutting down instance %s to change its shape", name)
+		err := client.interruptible(fmt.Sprintf("instance %s to shut down", name), func() error {
+			_, err := resClient.UpdateInstance(&compute.UpdateInstanceInput{
+				Name:         name,
+				ID:           d.Id(),
+				DesiredState: compute.InstanceDesiredShutdown,
+				Timeout:      d.Timeout(schema.TimeoutUpdate),
+				PollInterval: client.pollInterval,
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Error shutting down instance %s to change its shape: %s", name, err)
+		}
+	}
+
+	log.Printf("[DEBUG] Changing the shape of instance %s from %s to %s", name, oldShape, newShape)
+	if err := instanceActions.UpdateInstanceShape(name, d.Id(), newShape.(string)); err != nil {
+		shapeErr := fmt.Errorf("Error changing the shape of instance %s to %s: %s", name, newShape, err)
+		if compute.InstanceDesiredState(oldState.(string)) == compute.InstanceDesiredShutdown {
+			return shapeErr
+		}
+		err := client.interruptible(fmt.Sprintf("instance %s to start", name), func() error {
+			_, err := resClient.UpdateInstance(&compute.UpdateInstanceInput{
+				Name:         name,
+				ID:           d.Id(),
+				DesiredState: compute.InstanceDesiredRunning,
+				Timeout:      d.Timeout(schema.TimeoutUpdate),
+				PollInterval: client.pollInterval,
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s, and error starting it again: %s", shapeErr, err)
+		}
+		return shapeErr
+	}
+	return nil
+}
+
+// Detaches the storage volumes removed from the storage set, then attaches those added to it
+func updateInstanceStorage(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	computeClient, err := client.getComputeClient()
+	if err != nil {
+		return err
+	}
+	attachmentsClient := computeClient.StorageAttachments()
+
+	name := d.Get("name").(string)
+	o, n := d.GetChange("storage")
+	oldStorage, newStorage := o.(*schema.Set), n.(*schema.Set)
+	if err := checkStorageBootIndexes(name, d.Get("boot_order").([]interface{}), oldStorage, newStorage); err != nil {
+		return err
+	}
+
+	for _, v := range oldStorage.Difference(newStorage).List() {
+		attachment := v.(map[string]interface{})
+		log.Printf("[DEBUG] Detaching storage volume %s from instance %s", attachment["volume"], name)
+		input := &compute.DeleteStorageAttachmentInput{
+			Name:         attachment["name"].(string),
+			Timeout:      d.Timeout(schema.TimeoutUpdate),
+			PollInterval: client.pollInterval,
+		}
+		err := client.interruptible(fmt.Sprintf("storage volume %s to detach", attachment["volume"]), func() error {
+			return attachmentsClient.DeleteStorageAttachment(input)
+		})
+		if err != nil {
+			return fmt.Errorf("Error detaching storage volume %s from instance %s: %s", attachment["volume"], name, err)
+		}
+	}
+
+	added := newStorage.Difference(oldStorage).List()
+	if len(added) == 0 {
+		return nil
+	}
+	instance, err := computeClient.Instances().GetInstance(&compute.GetInstanceInput{
+		Name: name,
+		ID:   d.Id(),
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading instance %s: %s", name, err)
+	}
+	for _, v := range added {
+		attachment := v.(map[string]interface{})
+		index := attachment["index"].(int)
+		if !checkForEmptyIndex(instance.Storage, index) {
+			return fmt.Errorf("Storage index %d is already in use on instance %s", index, name)
+		}
+
+		log.Printf("[DEBUG] Attaching storage volume %s to instance %s at index %d", attachment["volume"], name, index)
+		input := &compute.CreateStorageAttachmentInput{
+			StorageVolumeName: attachment["volume"].(string),
+			InstanceName:      fmt.Sprintf("%s/%s", instance.Name, instance.ID),
+			Index:             index,
+			Timeout:           d.Timeout(schema.TimeoutUpdate),
+			PollInterval:      client.pollInterval,
+		}
+		var info *compute.StorageAttachmentInfo
+		err := client.interruptible(fmt.Sprintf("storage volume %s to attach", attachment["volume"]), func() (err error) {
+			info, err = attachmentsClient.CreateStorageAttachment(input)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Error attaching storage volume %s to instance %s: %s", attachment["volume"], name, err)
+		}
+		instance.Storage = append(instance.Storage, compute.StorageAttachment{
+			Index:             info.Index,
+			Name:              info.Name,
+			StorageVolumeName: info.StorageVolumeName,
+		})
+	}
+	return nil
+}
+
 func resourceInstanceDelete(d *schema.ResourceData, meta interface{}) error {
 	computeClient, err := meta.(*Client).getComputeClient()
 	if err != nil {
@@ -616,13 +1085,17 @@ func resourceInstanceDelete(d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 
 	input := &compute.DeleteInstanceInput{
-		ID:      d.Id(),
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
+		ID:           d.Id(),
+		Name:         name,
+		Timeout:      d.Timeout(schema.TimeoutDelete),
+		PollInterval: meta.(*Client).pollInterval,
 	}
 	log.Printf("[DEBUG] Deleting instance %s", name)
 
-	if err := resClient.DeleteInstance(input); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("instance %s to be deleted", name), func() error {
+		return resClient.DeleteInstance(input)
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting instance %s: %s", name, err)
 	}
 
@@ -642,21 +1115,18 @@ func getStorageAttachments(d *schema.ResourceData) []compute.StorageAttachmentIn
 	return storageAttachments
 }
 
-// Parses instance_attributes from a string to a map[string]interface and returns any errors.
+// Parses instance_attributes from a string to a map[string]interface, merges user_data into it,
+// and returns any errors.
 func getInstanceAttributes(d *schema.ResourceData) (map[string]interface{}, error) {
 	var attrs map[string]interface{}
 
-	// Empty instance attributes
-	attributes, ok := d.GetOk("instance_attributes")
-	if !ok {
-		return attrs, nil
-	}
-
-	if err := json.Unmarshal([]byte(attributes.(string)), &attrs); err != nil {
-		return attrs, fmt.Errorf("Cannot parse attributes as json: %s", err)
+	if attributes, ok := d.GetOk("instance_attributes"); ok {
+		if err := json.Unmarshal([]byte(attributes.(string)), &attrs); err != nil {
+			return attrs, fmt.Errorf("Cannot parse attributes as json: %s", err)
+		}
 	}
 
-	return attrs, nil
+	return mergeUserData(attrs, d.Get("user_data").([]interface{}))
 }
 
 // Reads attributes from the returned instance object, and sets the computed attributes string
@@ -982,8 +1452,24 @@ func readNetworkInterfaces(d *schema.ResourceData, ifaces map[string]compute.Net
 	return d.Set("networking_info", result)
 }
 
-// Flattens the returned slice of storage attachments to a map
+// Flattens the returned slice of storage attachments to a map. Storage attached at an index the
+// instance doesn't manage, e.g. with opc_compute_storage_attachment, is left out.
 func readStorageAttachments(d *schema.ResourceData, attachments []compute.StorageAttachment) error {
+	managed := make(map[int]bool)
+	for _, v := range d.Get("storage").(*schema.Set).List() {
+		managed[v.(map[string]interface{})["index"].(int)] = true
+	}
+
+	result := make([]compute.StorageAttachment, 0)
+	for _, attachment := range attachments {
+		if managed[attachment.Index] {
+			result = append(result, attachment)
+		}
+	}
+	return setStorageAttachments(d, result)
+}
+
+func setStorageAttachments(d *schema.ResourceData, attachments []compute.StorageAttachment) error {
 	result := make([]map[string]interface{}, 0)
 
 	if attachments == nil || len(attachments) == 0 {
@@ -2,12 +2,18 @@ package opc
 
 import (
 	"fmt"
+	"net"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-opc/opc/simulator"
 )
 
 const TestImageList = "/oracle/public/OL_7.2_UEKR4_x86_64"
@@ -282,6 +288,335 @@ func TestAccOPCInstance_Restart(t *testing.T) {
 	})
 }
 
+func TestAccOPCInstance_updateShape(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceShape(rInt, "oc3"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "shape", "oc3"),
+					func(s *terraform.State) error {
+						id = s.RootModule().Resources[resName].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccInstanceShape(rInt, "oc4"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "shape", "oc4"),
+					resource.TestCheckResourceAttr(resName, "state", string(compute.InstanceRunning)),
+					func(s *terraform.State) error {
+						if newID := s.RootModule().Resources[resName].Primary.ID; newID != id {
+							return fmt.Errorf("Expected instance %s to be resized in place, was replaced by %s", id, newID)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_updateShapeNonPersistent(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceNonPersistentShape(rInt, "oc3"),
+				Check:  testAccOPCCheckInstanceExists,
+			},
+			{
+				Config:      testAccInstanceNonPersistentShape(rInt, "oc4"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("doesn't boot from a persistent storage volume"),
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_updateStorage(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+	var id string
+
+	checkID := func(s *terraform.State) error {
+		if newID := s.RootModule().Resources[resName].Primary.ID; newID != id {
+			return fmt.Errorf("Expected the storage of instance %s to be changed in place, was replaced by %s", id, newID)
+		}
+		return nil
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceUpdateStorage(rInt, "foo"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "storage.#", "1"),
+					func(s *terraform.State) error {
+						id = s.RootModule().Resources[resName].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccInstanceUpdateStorage(rInt, "foo", "bar"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "storage.#", "2"),
+					checkID,
+				),
+			},
+			{
+				Config: testAccInstanceUpdateStorage(rInt, "bar"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "storage.#", "1"),
+					checkID,
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_updateBootStorage(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceBootStorage(rInt, 1),
+				Check:  testAccOPCCheckInstanceExists,
+			},
+			{
+				Config:      testAccInstanceBootStorage(rInt, 2),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("as the instance boots from it"),
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_userData(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceUserData(rInt, `{"enable_admin": true}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "user_data.0.packages.#", "1"),
+					resource.TestMatchResourceAttr(resName, "attributes", regexp.MustCompile(`"enable_admin":true`)),
+					resource.TestMatchResourceAttr(resName, "attributes",
+						regexp.MustCompile(`"userdata":\{"packages":\["httpd"\],"pre-bootstrap":\{"failonerror":true,"script":\["touch /tmp/hello"\]\}`)),
+				),
+			},
+			{
+				Config:      testAccInstanceUserData(rInt, `{"userdata": {"packages": ["git"]}}`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("userdata.packages is set in both instance_attributes and user_data"),
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_rebootTrigger(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceRebootTrigger(rInt, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					testAccOPCCheckInstanceConsole(resName, "Booting instance", "Rebooting instance"),
+					func(s *terraform.State) error {
+						id = s.RootModule().Resources[resName].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccInstanceRebootTrigger(rInt, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "reboot_trigger.config", "2"),
+					testAccOPCCheckInstanceConsole(resName, "Rebooting instance", ""),
+					func(s *terraform.State) error {
+						if newID := s.RootModule().Resources[resName].Primary.ID; newID != id {
+							return fmt.Errorf("Expected instance %s to be rebooted in place, was replaced by %s", id, newID)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// Checks the console output of the instance contains the expected text, and not the unexpected text
+func testAccOPCCheckInstanceConsole(resName, expected, unexpected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resName]
+		if !ok {
+			return fmt.Errorf("Resource %s not found", resName)
+		}
+		client := testAccProvider.Meta().(*Client).instanceActions
+		console, err := client.GetInstanceConsole(rs.Primary.Attributes["name"], rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error reading the console of instance %s: %s", rs.Primary.ID, err)
+		}
+		if !strings.Contains(console.Output, expected) {
+			return fmt.Errorf("Expected the console output to contain %q, got %q", expected, console.Output)
+		}
+		if unexpected != "" && strings.Contains(console.Output, unexpected) {
+			return fmt.Errorf("Expected the console output not to contain %q, got %q", unexpected, console.Output)
+		}
+		return nil
+	}
+}
+
+func TestAccOPCInstance_waitForPort(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	// Stands in for a service on the instance, as the simulated instances can't be reached
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceWaitFor(rInt, fmt.Sprintf(`
+    port    = %d
+    address = "127.0.0.1"`, port), "20m"),
+				Check: testAccOPCCheckInstanceExists,
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_waitForConsole(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccInstanceWaitFor(rInt, `address = "127.0.0.1"`, "20m"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("wait_for.0.address can only be set along with wait_for.0.port"),
+			},
+			{
+				Config: testAccInstanceWaitFor(rInt, `console_marker = "login:"`, "20m"),
+				Check:  testAccOPCCheckInstanceExists,
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_waitForTimeout(t *testing.T) {
+	rInt := acctest.RandInt()
+	config := testAccInstanceWaitFor(rInt, `console_marker = "never printed"`, "15s")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile("Timeout after .* waiting for \"never printed\" in the console"),
+			},
+			{
+				// The instance is tainted, so it's replaced
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestInstanceReadyAddress(t *testing.T) {
+	raw := map[string]interface{}{
+		"name":     "test",
+		"wait_for": []interface{}{map[string]interface{}{"port": 22}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceInstance().Schema, raw)
+	if _, err := instanceReadyAddress(d); err == nil || !strings.Contains(err.Error(), "has no IP address to wait for port 22 on") {
+		t.Fatalf("Expected an error for an instance without an IP address, got %v", err)
+	}
+
+	// Instances on IP networks only have the addresses of their interfaces
+	raw["networking_info"] = []interface{}{
+		map[string]interface{}{"index": 1, "ip_network": "network-b", "ip_address": "192.168.2.16"},
+		map[string]interface{}{"index": 0, "ip_network": "network-a", "ip_address": "192.168.1.16"},
+	}
+	d = schema.TestResourceDataRaw(t, resourceInstance().Schema, raw)
+	if address, err := instanceReadyAddress(d); err != nil || address != "192.168.1.16" {
+		t.Fatalf("Expected the address of the first interface, got %q, %v", address, err)
+	}
+
+	d.Set("ip_address", "10.0.0.16")
+	if address, err := instanceReadyAddress(d); err != nil || address != "10.0.0.16" {
+		t.Fatalf("Expected the shared network address, got %q, %v", address, err)
+	}
+
+	raw["wait_for"] = []interface{}{map[string]interface{}{"port": 22, "address": "129.150.0.16"}}
+	d = schema.TestResourceDataRaw(t, resourceInstance().Schema, raw)
+	if address, err := instanceReadyAddress(d); err != nil || address != "129.150.0.16" {
+		t.Fatalf("Expected the wait_for address, got %q, %v", address, err)
+	}
+}
+
 func testAccOPCCheckInstanceExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.Instances()
 
@@ -579,6 +914,173 @@ resource "opc_compute_instance" "test" {
 }`, rInt, rInt, rInt)
 }
 
+func testAccInstanceShape(rInt int, shape string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_image_list" "test" {
+  name        = "acc-test-instance-%d"
+  description = "testing instance shape changes"
+}
+
+resource "opc_compute_image_list_entry" "test" {
+  name           = "${opc_compute_image_list.test.name}"
+  machine_images = ["/oracle/public/oel_6.7_apaas_16.4.5_1610211300"]
+  version        = 1
+}
+
+resource "opc_compute_storage_volume" "test" {
+  name             = "acc-test-instance-%d"
+  size             = "20"
+  image_list       = "${opc_compute_image_list.test.name}"
+  image_list_entry = "${opc_compute_image_list_entry.test.version}"
+  bootable         = true
+}
+
+resource "opc_compute_instance" "test" {
+  name                  = "acc-test-instance-%d"
+  label                 = "TestAccOPCInstance_updateShape"
+  shape                 = "%s"
+  boot_order            = [1]
+  allow_stop_for_update = true
+
+  storage {
+    volume = "${opc_compute_storage_volume.test.name}"
+    index  = 1
+  }
+}`, rInt, rInt, rInt, shape)
+}
+
+func testAccInstanceNonPersistentShape(rInt int, shape string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name                  = "acc-test-instance-%d"
+  label                 = "TestAccOPCInstance_updateShapeNonPersistent"
+  shape                 = "%s"
+  image_list            = "%s"
+  allow_stop_for_update = true
+}`, rInt, shape, TestImageList)
+}
+
+func testAccInstanceUpdateStorage(rInt int, volumes ...string) string {
+	storage := ""
+	for _, volume := range volumes {
+		index := 1
+		if volume == "bar" {
+			index = 2
+		}
+		storage += fmt.Sprintf(`
+  storage {
+    volume = "${opc_compute_storage_volume.%s.name}"
+    index  = %d
+  }
+`, volume, index)
+	}
+	return fmt.Sprintf(`
+resource "opc_compute_storage_volume" "foo" {
+  name = "acc-test-instance-%d"
+  size = 1
+}
+
+resource "opc_compute_storage_volume" "bar" {
+  name = "acc-test-instance-2-%d"
+  size = 1
+}
+
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-instance-%d"
+  label      = "TestAccOPCInstance_updateStorage"
+  shape      = "oc3"
+  image_list = "%s"
+%s}`, rInt, rInt, rInt, TestImageList, storage)
+}
+
+func testAccInstanceBootStorage(rInt, index int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_image_list" "test" {
+  name        = "acc-test-instance-%d"
+  description = "testing instance storage changes"
+}
+
+resource "opc_compute_image_list_entry" "test" {
+  name           = "${opc_compute_image_list.test.name}"
+  machine_images = ["/oracle/public/oel_6.7_apaas_16.4.5_1610211300"]
+  version        = 1
+}
+
+resource "opc_compute_storage_volume" "test" {
+  name             = "acc-test-instance-%d"
+  size             = "20"
+  image_list       = "${opc_compute_image_list.test.name}"
+  image_list_entry = "${opc_compute_image_list_entry.test.version}"
+  bootable         = true
+}
+
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-instance-%d"
+  label      = "TestAccOPCInstance_updateBootStorage"
+  shape      = "oc3"
+  boot_order = [1]
+
+  storage {
+    volume = "${opc_compute_storage_volume.test.name}"
+    index  = %d
+  }
+}`, rInt, rInt, rInt, index)
+}
+
+func testAccInstanceUserData(rInt int, attributes string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name                = "acc-test-instance-%d"
+  label               = "TestAccOPCInstance_userData"
+  shape               = "oc3"
+  image_list          = "%s"
+  instance_attributes = <<JSON
+%s
+JSON
+
+  user_data {
+    packages = ["httpd"]
+
+    pre_bootstrap {
+      script        = ["touch /tmp/hello"]
+      fail_on_error = true
+    }
+  }
+}`, rInt, TestImageList, attributes)
+}
+
+func testAccInstanceRebootTrigger(rInt int, trigger string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-instance-%d"
+  label      = "TestAccOPCInstance_rebootTrigger"
+  shape      = "oc3"
+  image_list = "%s"
+
+  reboot_trigger = {
+    config = "%s"
+  }
+}`, rInt, TestImageList, trigger)
+}
+
+func testAccInstanceWaitFor(rInt int, waitFor, createTimeout string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-instance-%d"
+  label      = "TestAccOPCInstance_waitFor"
+  shape      = "oc3"
+  image_list = "%s"
+
+  wait_for {
+    %s
+  }
+
+  timeouts {
+    create = "%s"
+  }
+}`, rInt, TestImageList, waitFor, createTimeout)
+}
+
 func testAccInstanceHostname(rInt int) string {
 	return fmt.Sprintf(`
 resource "opc_compute_instance" "test" {
@@ -588,3 +1090,41 @@ resource "opc_compute_instance" "test" {
 	hostname = "testhostname-%d"
 }`, rInt, TestImageList, rInt)
 }
+
+func TestResourceInstanceCreate_relaunch(t *testing.T) {
+	s := simulator.New()
+	defer s.Close()
+
+	config := testSessionConfig(s)
+	config.PollInterval = time.Second
+	client, err := config.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := map[string]interface{}{
+		"name":       "relaunch",
+		"shape":      "oc3",
+		"image_list": TestImageList,
+	}
+
+	s.FailInstanceLaunches(1)
+	d := schema.TestResourceDataRaw(t, resourceInstance().Schema, raw)
+	if err := resourceInstanceCreate(d, client); err != nil {
+		t.Fatalf("Expected the instance to be launched again, got %s", err)
+	}
+	if state := d.Get("state").(string); state != "running" {
+		t.Fatalf("Expected the instance to be running, got %s", state)
+	}
+
+	// The instance which fails to start once retries are exhausted is kept, to be tainted
+	s.FailInstanceLaunches(2)
+	raw["name"] = "relaunch-failed"
+	d = schema.TestResourceDataRaw(t, resourceInstance().Schema, raw)
+	err = resourceInstanceCreate(d, client)
+	if err == nil || !strings.Contains(err.Error(), "No capacity is available") {
+		t.Fatalf("Expected the launch to fail, got %v", err)
+	}
+	if d.Id() == "" {
+		t.Fatal("Expected the instance which failed to start to be recorded in the state")
+	}
+}
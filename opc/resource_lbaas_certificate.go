@@ -67,7 +67,7 @@ func resourceLBaaSSSLCertificate() *schema.Resource {
 
 func resourceSSLCertificateCreate(d *schema.ResourceData, meta interface{}) error {
 
-	lbaasClient, err := meta.(*Client).getLBaaSClient()
+	lbaasClient, err := meta.(*Client).getLBaaSCreateClient()
 	if err != nil {
 		return err
 	}
@@ -139,7 +139,11 @@ func resourceSSLCertificateDelete(d *schema.ResourceData, meta interface{}) erro
 	sslCertClient := lbaasClient.SSLCertificateClient()
 	name := d.Id()
 
-	if _, err := sslCertClient.DeleteSSLCertificate(name); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("SSL certificate %s to be deleted", name), func() error {
+		_, err := sslCertClient.DeleteSSLCertificate(name)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting SSLCertificate: %v", err)
 	}
 	return nil
@@ -111,7 +111,7 @@ func resourceLBaaSListener() *schema.Resource {
 }
 
 func resourceListenerCreate(d *schema.ResourceData, meta interface{}) error {
-	lbaasClient, err := meta.(*Client).getLBaaSClient()
+	lbaasClient, err := meta.(*Client).getLBaaSCreateClient()
 	if err != nil {
 		return err
 	}
@@ -257,7 +257,11 @@ func resourceListenerUpdate(d *schema.ResourceData, meta interface{}) error {
 	input.Tags = updateOrRemoveStringListAttribute(d, "tags")
 	input.VirtualHosts = updateOrRemoveStringListAttribute(d, "virtual_hosts")
 
-	result, err := listenerClient.UpdateListener(lb, name, &input)
+	var result *lbaas.ListenerInfo
+	err = meta.(*Client).interruptible(fmt.Sprintf("listener %s to be updated", name), func() (err error) {
+		result, err = listenerClient.UpdateListener(lb, name, &input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating Listener: %s", err)
 	}
@@ -276,7 +280,11 @@ func resourceListenerDelete(d *schema.ResourceData, meta interface{}) error {
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := listenerClient.DeleteListener(lb, name); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("listener %s to be deleted", name), func() error {
+		_, err := listenerClient.DeleteListener(lb, name)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting Listener: %v", err)
 	}
 	return nil
@@ -111,7 +111,7 @@ func resourceLBaaSLoadBalancer() *schema.Resource {
 }
 
 func resourceOPCLoadBalancerCreate(d *schema.ResourceData, meta interface{}) error {
-	lbaasClient, err := meta.(*Client).getLBaaSClient()
+	lbaasClient, err := meta.(*Client).getLBaaSCreateClient()
 	if err != nil {
 		return err
 	}
@@ -253,7 +253,11 @@ func resourceOPCLoadBalancerUpdate(d *schema.ResourceData, meta interface{}) err
 	input.Policies = updateOrRemoveStringListAttribute(d, "policies")
 	input.Tags = updateOrRemoveStringListAttribute(d, "tags")
 
-	result, err := lbClient.UpdateLoadBalancer(lb, &input)
+	var result *lbaas.LoadBalancerInfo
+	err = meta.(*Client).interruptible(fmt.Sprintf("load balancer %s to be updated", lb.Name), func() (err error) {
+		result, err = lbClient.UpdateLoadBalancer(lb, &input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating LoadBalancer: %s", err)
 	}
@@ -271,7 +275,11 @@ func resourceOPCLoadBalancerDelete(d *schema.ResourceData, meta interface{}) err
 	lbClient := lbaasClient.LoadBalancerClient()
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := lbClient.DeleteLoadBalancer(lb); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("load balancer %s to be deleted", lb.Name), func() error {
+		_, err := lbClient.DeleteLoadBalancer(lb)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting LoadBalancer: %v", err)
 	}
 	return nil
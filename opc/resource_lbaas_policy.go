@@ -456,7 +456,7 @@ func resourceLBaaSPolicy() *schema.Resource {
 }
 
 func resourcePolicyCreate(d *schema.ResourceData, meta interface{}) error {
-	lbaasClient, err := meta.(*Client).getLBaaSClient()
+	lbaasClient, err := meta.(*Client).getLBaaSCreateClient()
 	if err != nil {
 		return err
 	}
@@ -635,7 +635,11 @@ func resourcePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
 		input.TrustedCertificatePolicyInfo = expandTrustedCertificatePolicy(d)
 	}
 
-	result, err := policyClient.UpdatePolicy(lb, name, input.Type, &input)
+	var result *lbaas.PolicyInfo
+	err = meta.(*Client).interruptible(fmt.Sprintf("policy %s to be updated", name), func() (err error) {
+		result, err = policyClient.UpdatePolicy(lb, name, input.Type, &input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating Policy: %s", err)
 	}
@@ -655,7 +659,11 @@ func resourcePolicyDelete(d *schema.ResourceData, meta interface{}) error {
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := policyClient.DeletePolicy(lb, name); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("policy %s to be deleted", name), func() error {
+		_, err := policyClient.DeletePolicy(lb, name)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting Policy: %v", err)
 	}
 	return nil
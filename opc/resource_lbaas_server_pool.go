@@ -144,7 +144,7 @@ func resourceLBaaSOriginServerPool() *schema.Resource {
 }
 
 func resourceOriginServerPoolCreate(d *schema.ResourceData, meta interface{}) error {
-	lbaasClient, err := meta.(*Client).getLBaaSClient()
+	lbaasClient, err := meta.(*Client).getLBaaSCreateClient()
 	if err != nil {
 		return err
 	}
@@ -297,7 +297,11 @@ func resourceOriginServerPoolUpdate(d *schema.ResourceData, meta interface{}) er
 
 	input.Tags = updateOrRemoveStringListAttribute(d, "tags")
 
-	result, err := serverPoolClient.UpdateOriginServerPool(lb, name, &input)
+	var result *lbaas.OriginServerPoolInfo
+	err = meta.(*Client).interruptible(fmt.Sprintf("server pool %s to be updated", name), func() (err error) {
+		result, err = serverPoolClient.UpdateOriginServerPool(lb, name, &input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating OriginServerPool: %s", err)
 	}
@@ -316,7 +320,11 @@ func resourceOriginServerPoolDelete(d *schema.ResourceData, meta interface{}) er
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := serverPoolClient.DeleteOriginServerPool(lb, name); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("server pool %s to be deleted", name), func() error {
+		_, err := serverPoolClient.DeleteOriginServerPool(lb, name)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting Server Pool: %v", err)
 	}
 	return nil
@@ -27,6 +27,25 @@ func resourceOPCOrchestratedInstance() *schema.Resource {
 			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
+		CustomizeDiff: func(diff *schema.ResourceDiff, meta interface{}) error {
+			for i := range diff.Get("instance").([]interface{}) {
+				key := fmt.Sprintf("instance.%d.shape", i)
+				if diff.HasChange(key) && diff.NewValueKnown(key) {
+					if err := validateShapeAvailable(meta, diff.Get(key).(string)); err != nil {
+						return err
+					}
+				}
+				key = fmt.Sprintf("instance.%d.instance_attributes", i)
+				if diff.NewValueKnown(key) {
+					userData := diff.Get(fmt.Sprintf("instance.%d.user_data", i)).([]interface{})
+					if err := checkUserData(diff.Get(key).(string), userData); err != nil {
+						return fmt.Errorf("Error in instance %d: %s", i, err)
+					}
+				}
+			}
+			return nil
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -63,7 +82,7 @@ func resourceOPCOrchestratedInstanceCreate(d *schema.ResourceData, meta interfac
 
 	log.Print("[DEBUG] Creating Orchestration")
 
-	computeClient, err := meta.(*Client).getComputeClient()
+	computeClient, err := meta.(*Client).getComputeCreateClient()
 	if err != nil {
 		return err
 	}
@@ -72,6 +91,7 @@ func resourceOPCOrchestratedInstanceCreate(d *schema.ResourceData, meta interfac
 		Name:         d.Get("name").(string),
 		DesiredState: compute.OrchestrationDesiredState(d.Get("desired_state").(string)),
 		Timeout:      d.Timeout(schema.TimeoutCreate),
+		PollInterval: meta.(*Client).pollInterval,
 	}
 
 	if v, ok := d.GetOk("description"); ok {
@@ -184,6 +204,7 @@ func resourceOPCOrchestratedInstanceUpdate(d *schema.ResourceData, meta interfac
 		Name:         d.Get("name").(string),
 		DesiredState: compute.OrchestrationDesiredState(d.Get("desired_state").(string)),
 		Timeout:      d.Timeout(schema.TimeoutUpdate),
+		PollInterval: meta.(*Client).pollInterval,
 		Version:      d.Get("version").(int),
 	}
 
@@ -198,7 +219,11 @@ func resourceOPCOrchestratedInstanceUpdate(d *schema.ResourceData, meta interfac
 
 	input.Objects = result.Objects
 
-	info, err := resClient.UpdateOrchestration(&input)
+	var info *compute.Orchestration
+	err = meta.(*Client).interruptible(fmt.Sprintf("orchestration %s to be updated", input.Name), func() (err error) {
+		info, err = resClient.UpdateOrchestration(&input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating Orchestration: %s", err)
 	}
@@ -217,12 +242,16 @@ func resourceOPCOrchestratedInstanceDelete(d *schema.ResourceData, meta interfac
 	name := d.Id()
 
 	input := &compute.DeleteOrchestrationInput{
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
+		Name:         name,
+		Timeout:      d.Timeout(schema.TimeoutDelete),
+		PollInterval: meta.(*Client).pollInterval,
 	}
 	log.Printf("[DEBUG] Deleting orchestration %s", name)
 
-	if err := resClient.DeleteOrchestration(input); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("orchestration %s to be deleted", name), func() error {
+		return resClient.DeleteOrchestration(input)
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting orchestration %s for instance %s: %s", name, d.Id(), err)
 	}
 
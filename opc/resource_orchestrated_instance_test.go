@@ -364,6 +364,33 @@ func TestAccOPCOrchestratedInstance_UserData(t *testing.T) {
 	})
 }
 
+func TestAccOPCOrchestratedInstance_UserDataBlock(t *testing.T) {
+	resName := "opc_compute_orchestrated_instance.test"
+	ri := acctest.RandInt()
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckOrchestrationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOrchestrationUserDataBlock(ri, "httpd"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationExists,
+					resource.TestCheckResourceAttr(resName, "instance.0.user_data.0.cloud_init", "#cloud-config\npackages:\n  - git\n"),
+					resource.TestCheckResourceAttr(resName, "instance.0.user_data.0.packages.0", "httpd"),
+				),
+			},
+			{
+				Config: testAccOrchestrationUserDataBlock(ri, "nginx"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationExists,
+					resource.TestCheckResourceAttr(resName, "instance.0.user_data.0.packages.0", "nginx"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckOrchestrationExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.Orchestrations()
 
@@ -677,3 +704,24 @@ JSON
 }
   `, rInt, rInt)
 }
+
+func testAccOrchestrationUserDataBlock(rInt int, pkg string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_orchestrated_instance" "test" {
+  name          = "test_orchestration-%d"
+  desired_state = "active"
+
+  instance {
+    name       = "acc-test-instance-%d"
+    label      = "TestAccOPCOrchestratedInstance_UserDataBlock"
+    shape      = "oc3"
+    image_list = "/oracle/public/OL_7.2_UEKR4_x86_64"
+
+    user_data {
+      cloud_init = "#cloud-config\npackages:\n  - git\n"
+      packages   = ["%s"]
+    }
+  }
+}
+`, rInt, rInt, pkg)
+}
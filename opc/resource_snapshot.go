@@ -57,7 +57,7 @@ func resourceOPCSnapshot() *schema.Resource {
 }
 
 func resourceOPCSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
-	computeClient, err := meta.(*Client).getComputeClient()
+	computeClient, err := meta.(*Client).getComputeCreateClient()
 	if err != nil {
 		return err
 	}
@@ -66,8 +66,9 @@ func resourceOPCSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
 	instance := d.Get("instance").(string)
 
 	input := compute.CreateSnapshotInput{
-		Instance: instance,
-		Timeout:  d.Timeout(schema.TimeoutCreate),
+		Instance:     instance,
+		Timeout:      d.Timeout(schema.TimeoutCreate),
+		PollInterval: meta.(*Client).pollInterval,
 	}
 
 	if account, ok := d.GetOk("account"); ok {
@@ -146,8 +147,12 @@ func resourceOPCSnapshotDelete(d *schema.ResourceData, meta interface{}) error {
 		Snapshot:     name,
 		MachineImage: result.MachineImage,
 		Timeout:      d.Timeout(schema.TimeoutDelete),
+		PollInterval: meta.(*Client).pollInterval,
 	}
-	if err := snapshotClient.DeleteSnapshot(machineImageClient, &input); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("snapshot %s to be deleted", name), func() error {
+		return snapshotClient.DeleteSnapshot(machineImageClient, &input)
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting snapshot %s: %s", name, err)
 	}
 
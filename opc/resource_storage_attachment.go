@@ -53,7 +53,7 @@ func resourceOPCStorageAttachmentCreate(d *schema.ResourceData, meta interface{}
 	log.Print("[DEBUG] Creating storage_attachment")
 
 	volumeName := d.Get("storage_volume").(string)
-	computeClient, err := meta.(*Client).getComputeClient()
+	computeClient, err := meta.(*Client).getComputeCreateClient()
 	if err != nil {
 		return err
 	}
@@ -91,12 +91,13 @@ func resourceOPCStorageAttachmentCreate(d *schema.ResourceData, meta interface{}
 		return fmt.Errorf("Storage index %d is already in use on instance %s", volumeIndex, instanceName)
 	}
 
-	storageAttachmentClient := meta.(*Client).computeClient.StorageAttachments()
+	storageAttachmentClient := meta.(*Client).computeCreateClient.StorageAttachments()
 	input := compute.CreateStorageAttachmentInput{
 		StorageVolumeName: storageVolume.Name,
 		InstanceName:      fmt.Sprintf("%s/%s", instance.Name, instance.ID),
 		Index:             volumeIndex,
 		Timeout:           d.Timeout(schema.TimeoutCreate),
+		PollInterval:      meta.(*Client).pollInterval,
 	}
 
 	info, err := storageAttachmentClient.CreateStorageAttachment(&input)
@@ -166,10 +167,14 @@ func resourceOPCStorageAttachmentDelete(d *schema.ResourceData, meta interface{}
 	log.Printf("[DEBUG] Deleting StorageAttachment: %v", name)
 
 	input := compute.DeleteStorageAttachmentInput{
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
+		Name:         name,
+		Timeout:      d.Timeout(schema.TimeoutDelete),
+		PollInterval: meta.(*Client).pollInterval,
 	}
-	if err := resClient.DeleteStorageAttachment(&input); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("storage attachment %s to be deleted", name), func() error {
+		return resClient.DeleteStorageAttachment(&input)
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting StorageAttachment")
 	}
 	return nil
@@ -2,10 +2,22 @@ package opc
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/storage"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+const (
+	hVersionsLocation     = "X-Versions-Location"
+	hHistoryLocation      = "X-History-Location"
+	hPolicyGeoreplication = "X-Container-Meta-Policy-Georeplication"
+	hStorageClass         = "X-Storage-Class"
+
+	storageClassStandard = "Standard"
+	storageClassArchive  = "Archive"
 )
 
 func resourceOPCStorageContainer() *schema.Resource {
@@ -72,12 +84,31 @@ func resourceOPCStorageContainer() *schema.Resource {
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
-			// "georeplication_policy": {
-			// 	Type:     schema.TypeList,
-			// 	Optional: true,
-			// 	Computed: true,
-			// 	Elem:     &schema.Schema{Type: schema.TypeString},
-			// },
+			"georeplication_policy": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"versions_location": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"history_location"},
+			},
+			"history_location": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"versions_location"},
+			},
+			"storage_class": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  storageClassStandard,
+				ValidateFunc: validation.StringInSlice([]string{
+					storageClassStandard,
+					storageClassArchive,
+				}, false),
+			},
 		},
 	}
 }
@@ -127,6 +158,17 @@ func resourceOPCStorageContainerCreate(d *schema.ResourceData, meta interface{})
 		input.CustomMetadata = metadata
 	}
 
+	// The storage client can't set the storage class, which can only be set when the container is created
+	if storageClass := d.Get("storage_class").(string); storageClass != storageClassStandard {
+		storageAPIClient, err := meta.(*Client).getStorageAPIClient()
+		if err != nil {
+			return err
+		}
+		if err := storageAPIClient.CreateContainer(input.Name, map[string]string{hStorageClass: storageClass}); err != nil {
+			return fmt.Errorf("Error creating Storage Container: %s", err)
+		}
+	}
+
 	info, err := storageClient.CreateContainer(&input)
 	if err != nil {
 		return fmt.Errorf("Error creating Storage Container: %s", err)
@@ -134,6 +176,10 @@ func resourceOPCStorageContainerCreate(d *schema.ResourceData, meta interface{})
 
 	d.SetId(info.Name)
 
+	if err := updateStorageContainerHeaders(d, meta); err != nil {
+		return err
+	}
+
 	return resourceOPCStorageContainerRead(d, meta)
 }
 
@@ -184,6 +230,26 @@ func resourceOPCStorageContainerRead(d *schema.ResourceData, meta interface{}) e
 		return err
 	}
 
+	// The storage class, versioning and georeplication headers aren't read by the storage client
+	storageAPIClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+	headers, err := storageAPIClient.GetContainerHeaders(name)
+	if err != nil {
+		return fmt.Errorf("Error reading Storage Container '%s': %s", name, err)
+	}
+	d.Set("versions_location", headers.Get(hVersionsLocation))
+	d.Set("history_location", headers.Get(hHistoryLocation))
+	storageClass := headers.Get(hStorageClass)
+	if storageClass == "" {
+		storageClass = storageClassStandard
+	}
+	d.Set("storage_class", storageClass)
+	if err := setStringList(d, "georeplication_policy", strings.Fields(headers.Get(hPolicyGeoreplication))); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -269,9 +335,50 @@ func resourceOPCStorageContainerUpdate(d *schema.ResourceData, meta interface{})
 
 	d.SetId(info.Name)
 
+	if err := updateStorageContainerHeaders(d, meta); err != nil {
+		return err
+	}
+
 	return resourceOPCStorageContainerRead(d, meta)
 }
 
+// Sets the versioning and georeplication headers, which the storage client doesn't support
+func updateStorageContainerHeaders(d *schema.ResourceData, meta interface{}) error {
+	headers := make(map[string]string)
+	for attr, header := range map[string]string{
+		"versions_location": hVersionsLocation,
+		"history_location":  hHistoryLocation,
+	} {
+		if !d.HasChange(attr) {
+			continue
+		}
+		if v := d.Get(attr).(string); v != "" {
+			headers[header] = v
+		} else {
+			headers["X-Remove-"+strings.TrimPrefix(header, "X-")] = "x"
+		}
+	}
+	if d.HasChange("georeplication_policy") {
+		if policy := getStringList(d, "georeplication_policy"); len(policy) > 0 {
+			headers[hPolicyGeoreplication] = strings.Join(policy, " ")
+		} else {
+			headers["X-Remove-Container-Meta-Policy-Georeplication"] = "x"
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+	if err := storageClient.UpdateContainerHeaders(d.Id(), headers); err != nil {
+		return fmt.Errorf("Error updating Storage Container '%s': %s", d.Id(), err)
+	}
+	return nil
+}
+
 // get keys from a map
 func getKeys(m map[string]interface{}) []string {
 	keys := []string{}
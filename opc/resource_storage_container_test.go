@@ -2,6 +2,7 @@ package opc
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-oracle-terraform/storage"
@@ -82,6 +83,96 @@ func TestAccOPCStorageContainer_Updated(t *testing.T) {
 	})
 }
 
+func TestAccOPCStorageContainer_versioning(t *testing.T) {
+	containerResourceName := "opc_storage_container.test"
+	ri := acctest.RandInt()
+	container := fmt.Sprintf("acc-storage-container-%d", ri)
+	versions := fmt.Sprintf("acc-storage-container-versions-%d", ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageContainerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageContainerVersioning(ri, `versions_location = "${opc_storage_container.versions.name}"
+  georeplication_policy = ["us2", "us6"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageContainerExists,
+					resource.TestCheckResourceAttr(containerResourceName, "versions_location", versions),
+					resource.TestCheckResourceAttr(containerResourceName, "history_location", ""),
+					resource.TestCheckResourceAttr(containerResourceName, "georeplication_policy.#", "2"),
+					resource.TestCheckResourceAttr(containerResourceName, "georeplication_policy.0", "us2"),
+					resource.TestCheckResourceAttr(containerResourceName, "georeplication_policy.1", "us6"),
+					testAccCheckStorageContainerVersions(container, versions, 1),
+				),
+			},
+			{
+				Config: testAccOPCStorageContainerVersioning(ri, `history_location = "${opc_storage_container.versions.name}"
+  georeplication_policy = ["us2"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(containerResourceName, "versions_location", ""),
+					resource.TestCheckResourceAttr(containerResourceName, "history_location", versions),
+					resource.TestCheckResourceAttr(containerResourceName, "georeplication_policy.#", "1"),
+					testAccCheckStorageContainerVersions(container, versions, 1),
+				),
+			},
+			{
+				Config: testAccOPCStorageContainerVersioning(ri, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(containerResourceName, "versions_location", ""),
+					resource.TestCheckResourceAttr(containerResourceName, "history_location", ""),
+					resource.TestCheckResourceAttr(containerResourceName, "georeplication_policy.#", "0"),
+					testAccCheckStorageContainerVersions(container, versions, 0),
+				),
+			},
+		},
+	})
+}
+
+// Overwrites an object in the container, checking the number of previous versions kept. The object
+// and its versions are deleted afterwards, so the containers can be destroyed.
+func testAccCheckStorageContainerVersions(container, versions string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client).storageAPIClient
+		for _, content := range []string{"v1", "v2"} {
+			if _, err := client.PutObject(container, "test", nil, strings.NewReader(content)); err != nil {
+				return err
+			}
+		}
+
+		objects, err := client.ListObjects(&storageListObjectsInput{Container: versions})
+		if err != nil {
+			return err
+		}
+		if len(objects) != count {
+			return fmt.Errorf("Expected %d versions in %s, found %d", count, versions, len(objects))
+		}
+
+		// The versions are deleted first, as deleting the object restores the previous version in the
+		// stack mode, and are deleted again, as it archives the object in the history mode
+		deleteVersions := func() error {
+			objects, err := client.ListObjects(&storageListObjectsInput{Container: versions})
+			if err != nil {
+				return err
+			}
+			for _, object := range objects {
+				if err := client.DeleteObject(versions, object.Name, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := deleteVersions(); err != nil {
+			return err
+		}
+		if err := client.DeleteObject(container, "test", nil); err != nil {
+			return err
+		}
+		return deleteVersions()
+	}
+}
+
 func testAccCheckStorageContainerExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).storageClient
 
@@ -156,3 +247,16 @@ resource "opc_storage_container" "test" {
 }
 `, rInt)
 }
+
+func testAccOPCStorageContainerVersioning(rInt int, versioning string) string {
+	return fmt.Sprintf(`
+resource "opc_storage_container" "versions" {
+  name = "acc-storage-container-versions-%d"
+}
+
+resource "opc_storage_container" "test" {
+  name = "acc-storage-container-%d"
+  %s
+}
+`, rInt, rInt, versioning)
+}
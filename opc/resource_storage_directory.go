@@ -0,0 +1,364 @@
+package opc
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/client"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mitchellh/go-homedir"
+)
+
+// The prefix must end in a slash, so the objects listed under it are only those in its directory,
+// e.g. assets/ doesn't list assets2/logo.png
+var storageDirectoryPrefixRegexp = regexp.MustCompile(`^$|/$`)
+
+func resourceOPCStorageDirectory() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceOPCStorageDirectoryCreate,
+		Read:          resourceOPCStorageDirectoryRead,
+		Update:        resourceOPCStorageDirectoryUpdate,
+		Delete:        resourceOPCStorageDirectoryDelete,
+		CustomizeDiff: resourceOPCStorageDirectoryCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"container": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the storage container",
+			},
+			"source": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Path of the local directory to upload",
+			},
+			"prefix": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(storageDirectoryPrefixRegexp, "must end with a /, e.g. assets/"),
+				Description:  "Prefix added to the path of each file to make the name of its object, e.g. assets/",
+			},
+			"include": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Glob patterns of the files to upload. Defaults to every file",
+			},
+			"exclude": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Glob patterns of the files not to upload",
+			},
+			"delete_unmanaged": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete objects under the prefix which aren't in the directory, even if they weren't uploaded by this resource",
+			},
+			"parallelism": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4,
+				Description:  "The number of files to upload or delete at once",
+				ValidateFunc: validation.IntBetween(1, 32),
+			},
+			"files": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The MD5 checksum of each file, keyed by its path relative to the directory",
+			},
+		},
+	}
+}
+
+// Records the checksums of the local files in the plan, so it shows which files will be uploaded or deleted
+func resourceOPCStorageDirectoryCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"source", "include", "exclude"} {
+		if !diff.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	files, err := localStorageDirectoryFiles(diff.Get("source").(string), storageDirectoryPatterns(diff.Get("include")), storageDirectoryPatterns(diff.Get("exclude")))
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(diff.Get("files"), files) {
+		return nil
+	}
+	return diff.SetNew("files", files)
+}
+
+func resourceOPCStorageDirectoryCreate(d *schema.ResourceData, meta interface{}) error {
+	container := d.Get("container").(string)
+	prefix := d.Get("prefix").(string)
+
+	if err := syncStorageDirectory(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", container, prefix))
+	return resourceOPCStorageDirectoryRead(d, meta)
+}
+
+// Records the checksums of the objects for the files in the directory, so files which were changed or deleted
+// outside of Terraform are uploaded again. With delete_unmanaged, every other object under the prefix is recorded
+// too, so the plan shows they will be deleted.
+func resourceOPCStorageDirectoryRead(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	container := d.Get("container").(string)
+	prefix := d.Get("prefix").(string)
+	managed := d.Get("files").(map[string]interface{})
+	deleteUnmanaged := d.Get("delete_unmanaged").(bool)
+	include := getStringList(d, "include")
+	exclude := getStringList(d, "exclude")
+
+	objects, err := storageClient.ListObjects(&storageListObjectsInput{
+		Container: container,
+		Prefix:    prefix,
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading Storage Directory (%s): %s", d.Id(), err)
+	}
+
+	files := make(map[string]interface{})
+	for _, object := range objects {
+		file := strings.TrimPrefix(object.Name, prefix)
+		if _, ok := managed[file]; ok || (deleteUnmanaged && storageDirectoryFileMatches(file, include, exclude)) {
+			files[file] = object.Hash
+		}
+	}
+	if err := d.Set("files", files); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourceOPCStorageDirectoryUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := syncStorageDirectory(d, meta); err != nil {
+		return err
+	}
+
+	return resourceOPCStorageDirectoryRead(d, meta)
+}
+
+func resourceOPCStorageDirectoryDelete(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	container := d.Get("container").(string)
+	prefix := d.Get("prefix").(string)
+	var names []string
+	for file := range d.Get("files").(map[string]interface{}) {
+		names = append(names, prefix+file)
+	}
+
+	return storageParallel(len(names), d.Get("parallelism").(int), func(i int) error {
+		log.Printf("[DEBUG] Deleting storage object %s/%s", container, names[i])
+		if err := storageClient.DeleteObject(container, names[i], nil); err != nil && !client.WasNotFoundError(err) {
+			return fmt.Errorf("Error deleting storage object %s/%s: %s", container, names[i], err)
+		}
+		return nil
+	})
+}
+
+// Uploads the files which aren't in the container, or have changed, and deletes the objects of files which were
+// uploaded before but are no longer in the directory, or with delete_unmanaged every object which isn't.
+func syncStorageDirectory(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	container := d.Get("container").(string)
+	prefix := d.Get("prefix").(string)
+	include := getStringList(d, "include")
+	exclude := getStringList(d, "exclude")
+	parallelism := d.Get("parallelism").(int)
+
+	source, err := homedir.Expand(d.Get("source").(string))
+	if err != nil {
+		return fmt.Errorf("Error expanding homedir in source (%s): %s", d.Get("source").(string), err)
+	}
+	files, err := localStorageDirectoryFiles(source, include, exclude)
+	if err != nil {
+		return err
+	}
+
+	objects, err := storageClient.ListObjects(&storageListObjectsInput{
+		Container: container,
+		Prefix:    prefix,
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing objects in %s: %s", container, err)
+	}
+	remote := make(map[string]string)
+	for _, object := range objects {
+		remote[strings.TrimPrefix(object.Name, prefix)] = object.Hash
+	}
+
+	var uploads []string
+	for file, etag := range files {
+		if remote[file] != etag {
+			uploads = append(uploads, file)
+		}
+	}
+	sort.Strings(uploads)
+
+	var deletes []string
+	old, _ := d.GetChange("files")
+	for file := range remote {
+		if _, ok := files[file]; ok {
+			continue
+		}
+		if _, ok := old.(map[string]interface{})[file]; ok || (d.Get("delete_unmanaged").(bool) && storageDirectoryFileMatches(file, include, exclude)) {
+			deletes = append(deletes, file)
+		}
+	}
+	sort.Strings(deletes)
+
+	err = storageParallel(len(uploads), parallelism, func(i int) error {
+		file := uploads[i]
+		log.Printf("[DEBUG] Uploading %s to storage object %s/%s", file, container, prefix+file)
+		if err := putStorageDirectoryFile(storageClient, container, prefix+file, filepath.Join(source, filepath.FromSlash(file)), files[file].(string)); err != nil {
+			return fmt.Errorf("Error uploading %s to storage object %s/%s: %s", file, container, prefix+file, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return storageParallel(len(deletes), parallelism, func(i int) error {
+		name := prefix + deletes[i]
+		log.Printf("[DEBUG] Deleting storage object %s/%s", container, name)
+		if err := storageClient.DeleteObject(container, name, nil); err != nil && !client.WasNotFoundError(err) {
+			return fmt.Errorf("Error deleting storage object %s/%s: %s", container, name, err)
+		}
+		return nil
+	})
+}
+
+func putStorageDirectoryFile(storageClient *storageAPIClient, container, name, path, etag string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// The API checks the content against the etag, in case the file changed since the plan
+	headers := map[string]string{"Etag": etag}
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	_, err = storageClient.PutObject(container, name, headers, io.NewSectionReader(file, 0, info.Size()))
+	return err
+}
+
+// Returns the MD5 checksum of each file in the directory which matches the patterns,
+// keyed by its path relative to the directory
+func localStorageDirectoryFiles(source string, include, exclude []string) (map[string]interface{}, error) {
+	source, err := homedir.Expand(source)
+	if err != nil {
+		return nil, fmt.Errorf("Error expanding homedir in source (%s): %s", source, err)
+	}
+
+	files := make(map[string]interface{})
+	err = filepath.Walk(source, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(source, filePath)
+		if err != nil {
+			return err
+		}
+		file := filepath.ToSlash(rel)
+		if !storageDirectoryFileMatches(file, include, exclude) {
+			return nil
+		}
+
+		etag, err := localStorageObjectEtag(filePath, 0)
+		if err != nil {
+			return err
+		}
+		files[file] = etag
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading source directory (%s): %s", source, err)
+	}
+	return files, nil
+}
+
+// Checks a file matches one of the include patterns, if there are any, and none of the exclude patterns
+func storageDirectoryFileMatches(file string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if storageDirectoryGlobMatch(pattern, file) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if storageDirectoryGlobMatch(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches a slash separated path against a glob pattern. A pattern without a slash, e.g. *.html, matches
+// the name of a file in any directory, and a pattern ending in /** matches every file under a directory.
+func storageDirectoryGlobMatch(pattern, file string) bool {
+	if dir := strings.TrimSuffix(pattern, "/**"); dir != pattern {
+		for d := path.Dir(file); d != "."; d = path.Dir(d) {
+			if ok, _ := path.Match(dir, d); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		file = path.Base(file)
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
+
+// Returns the patterns of a list of include or exclude patterns
+func storageDirectoryPatterns(v interface{}) []string {
+	var patterns []string
+	for _, pattern := range v.([]interface{}) {
+		patterns = append(patterns, pattern.(string))
+	}
+	return patterns
+}
@@ -0,0 +1,157 @@
+package opc
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccOPCStorageDirectory_basic(t *testing.T) {
+	resName := "opc_storage_directory.test"
+	rInt := acctest.RandInt()
+	container := fmt.Sprintf("acc-test-directory-%d", rInt)
+
+	dir, err := ioutil.TempDir("", "opc-storage-directory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("index.html", "<html></html>")
+	writeFile("css/site.css", "body {}")
+	writeFile("notes.txt", "not published")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageContainerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageDirectory_basic(rInt, dir, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "files.%", "2"),
+					resource.TestCheckResourceAttr(resName, "files.index.html", fmt.Sprintf("%x", md5.Sum([]byte("<html></html>")))),
+					resource.TestCheckResourceAttr(resName, "files.css/site.css", fmt.Sprintf("%x", md5.Sum([]byte("body {}")))),
+					testAccCheckStorageDirectoryObjects(container, "site/css/site.css", "site/index.html"),
+				),
+			},
+			{
+				// Changing a local file shows in the plan
+				PreConfig:          func() { writeFile("css/site.css", "body { margin: 0 }") },
+				Config:             testAccOPCStorageDirectory_basic(rInt, dir, false),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// Removed files are deleted, along with objects which weren't uploaded by the resource
+				PreConfig: func() {
+					if err := os.Remove(filepath.Join(dir, "index.html")); err != nil {
+						t.Fatal(err)
+					}
+					client := testAccProvider.Meta().(*Client).storageAPIClient
+					if _, err := client.PutObject(container, "site/stale.html", nil, strings.NewReader("stale")); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccOPCStorageDirectory_basic(rInt, dir, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "files.%", "1"),
+					resource.TestCheckResourceAttr(resName, "files.css/site.css", fmt.Sprintf("%x", md5.Sum([]byte("body { margin: 0 }")))),
+					testAccCheckStorageDirectoryObjects(container, "site/css/site.css"),
+				),
+			},
+		},
+	})
+}
+
+func TestStorageDirectoryGlobMatch(t *testing.T) {
+	cases := []struct {
+		Pattern string
+		File    string
+		Match   bool
+	}{
+		{"*.html", "index.html", true},
+		{"*.html", "docs/index.html", true},
+		{"*.html", "index.htm", false},
+		{"docs/*.html", "docs/index.html", true},
+		{"docs/*.html", "index.html", false},
+		{"docs/*.html", "docs/api/index.html", false},
+		{"docs/**", "docs/api/index.html", true},
+		{"docs/**", "docs/index.html", true},
+		{"docs/**", "index.html", false},
+		{"*/api/**", "docs/api/v1/index.html", true},
+	}
+
+	for _, tc := range cases {
+		if match := storageDirectoryGlobMatch(tc.Pattern, tc.File); match != tc.Match {
+			t.Errorf("Expected %q matching %q to be %t, got %t", tc.Pattern, tc.File, tc.Match, match)
+		}
+	}
+}
+
+func TestStorageDirectoryPrefixValidation(t *testing.T) {
+	validate := resourceOPCStorageDirectory().Schema["prefix"].ValidateFunc
+	for prefix, valid := range map[string]bool{
+		"":             true,
+		"assets/":      true,
+		"site/assets/": true,
+		"assets":       false,
+		"assets-":      false,
+	} {
+		if _, errs := validate(prefix, "prefix"); (len(errs) == 0) != valid {
+			t.Errorf("Expected prefix %q to be valid: %t, got %v", prefix, valid, errs)
+		}
+	}
+}
+
+// Checks the names of the objects in the container are the expected names
+func testAccCheckStorageDirectoryObjects(container string, expected ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client).storageAPIClient
+		objects, err := client.ListObjects(&storageListObjectsInput{Container: container})
+		if err != nil {
+			return err
+		}
+		var names []string
+		for _, object := range objects {
+			names = append(names, object.Name)
+		}
+		if !reflect.DeepEqual(names, expected) {
+			return fmt.Errorf("Expected objects %v in %s, got %v", expected, container, names)
+		}
+		return nil
+	}
+}
+
+func testAccOPCStorageDirectory_basic(rInt int, dir string, deleteUnmanaged bool) string {
+	return fmt.Sprintf(`
+resource "opc_storage_container" "test" {
+  name = "acc-test-directory-%d"
+}
+
+resource "opc_storage_directory" "test" {
+  container        = "${opc_storage_container.test.name}"
+  source           = "%s"
+  prefix           = "site/"
+  exclude          = ["*.txt"]
+  delete_unmanaged = %t
+}
+`, rInt, filepath.ToSlash(dir), deleteUnmanaged)
+}
@@ -2,12 +2,18 @@ package opc
 
 import (
 	"bytes"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/go-oracle-terraform/storage"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/mitchellh/go-homedir"
 )
 
@@ -15,10 +21,12 @@ func resourceOPCStorageObject() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceOPCStorageObjectCreate,
 		Read:   resourceOPCStorageObjectRead,
+		Update: resourceOPCStorageObjectUpdate,
 		Delete: resourceOPCStorageObjectDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceOPCStorageObjectCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -50,20 +58,17 @@ func resourceOPCStorageObject() *schema.Resource {
 			"content_disposition": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 				Description: "Overrides the behavior of the browser",
 			},
 			"content_encoding": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 				Description: "Set the content-encoding metadata",
 			},
 			"content_type": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
 				Description: "Set the MIME type for the object",
 			},
 			"copy_from": {
@@ -82,11 +87,18 @@ func resourceOPCStorageObject() *schema.Resource {
 				},
 			},
 			"delete_at": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Computed:    true,
-				ForceNew:    true,
-				Description: "The date and time in UNIX Epoch time stamp format when the system removes the object",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"delete_after"},
+				Description:   "The date and time in UNIX Epoch time stamp format when the system removes the object",
+			},
+			"delete_after": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"delete_at"},
+				ValidateFunc:  validation.IntAtLeast(1),
+				Description:   "The number of seconds after which the system removes the object",
 			},
 			"etag": {
 				Type:        schema.TypeString,
@@ -99,9 +111,40 @@ func resourceOPCStorageObject() *schema.Resource {
 				Type:        schema.TypeMap,
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
 				Description: "The object metadata",
 			},
+			"segment_size": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "Upload the file in segments of this many bytes, joined by a large object manifest",
+				ConflictsWith: []string{"content", "copy_from", "etag", "transfer_encoding"},
+				ValidateFunc:  validateStorageSegmentSize,
+			},
+			"segment_parallelism": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "The number of segments to upload at once. Defaults to 1",
+				ValidateFunc: validation.IntBetween(1, 32),
+			},
+			"segment_container": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The container to upload segments to. Defaults to the container name with a _segments suffix",
+			},
+			"manifest_type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The type of large object manifest for segmented uploads, static or dynamic. Defaults to static",
+				ValidateFunc: validation.StringInSlice([]string{
+					storageManifestStatic,
+					storageManifestDynamic,
+				}, false),
+			},
 			"transfer_encoding": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -165,6 +208,10 @@ func resourceOPCStorageObjectCreate(d *schema.ResourceData, meta interface{}) er
 		Container: d.Get("container").(string),
 	}
 
+	if _, ok := d.GetOk("segment_size"); ok {
+		return resourceOPCStorageObjectCreateSegmented(d, meta)
+	}
+
 	// Check for `content` or `file`.
 	if v, ok := d.GetOk("content"); ok {
 		// Read content as io.ReadSeeker
@@ -227,6 +274,14 @@ func resourceOPCStorageObjectCreate(d *schema.ResourceData, meta interface{}) er
 	}
 
 	d.SetId(result.ID)
+
+	// The storage client can't set X-Delete-After, so it's set once the object is uploaded
+	if _, ok := d.GetOk("delete_after"); ok {
+		if err := updateStorageObjectHeaders(d, meta); err != nil {
+			return err
+		}
+	}
+
 	return resourceOPCStorageObjectRead(d, meta)
 }
 
@@ -237,8 +292,10 @@ func resourceOPCStorageObjectRead(d *schema.ResourceData, meta interface{}) erro
 	}
 	resClient := storageClient.Objects()
 
+	container, name := parseStorageObjectID(d.Id())
 	input := &storage.GetObjectInput{
-		ID: d.Id(),
+		Container: container,
+		Name:      name,
 	}
 
 	result, err := resClient.GetObject(input)
@@ -258,7 +315,8 @@ func resourceOPCStorageObjectRead(d *schema.ResourceData, meta interface{}) erro
 	d.Set("content_length", result.ContentLength)
 	d.Set("content_type", result.ContentType)
 	d.Set("date", result.Date)
-	d.Set("etag", result.Etag)
+	// The etag of a large object is quoted
+	d.Set("etag", strings.Trim(result.Etag, "\""))
 	d.Set("last_modified", result.LastModified)
 	d.Set("delete_at", result.DeleteAt)
 	d.Set("object_manifest", result.ObjectManifest)
@@ -269,15 +327,91 @@ func resourceOPCStorageObjectRead(d *schema.ResourceData, meta interface{}) erro
 	return nil
 }
 
+// Updates the headers of the object in place. The content is only uploaded again when it changes,
+// as those attributes force a new object.
+func resourceOPCStorageObjectUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := updateStorageObjectHeaders(d, meta); err != nil {
+		return err
+	}
+
+	return resourceOPCStorageObjectRead(d, meta)
+}
+
+func updateStorageObjectHeaders(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	headers := storageObjectHeaders(d)
+	// Every header is sent, as the API replaces all of the metadata of the object
+	for _, header := range []string{"Content-Disposition", "Content-Encoding"} {
+		if _, ok := headers[header]; !ok {
+			headers[header] = ""
+		}
+	}
+	if v, ok := d.GetOk("object_manifest"); ok {
+		headers["X-Object-Manifest"] = v.(string)
+	}
+
+	container, name := parseStorageObjectID(d.Id())
+	if err := storageClient.UpdateObjectMetadata(container, name, headers); err != nil {
+		return fmt.Errorf("Error updating Storage Container Object (%s): %s", d.Id(), err)
+	}
+	return nil
+}
+
+// Returns the headers set from the attributes of the object, which can be changed without uploading it again
+func storageObjectHeaders(d *schema.ResourceData) map[string]string {
+	headers := make(map[string]string)
+	if v, ok := d.GetOk("content_disposition"); ok {
+		headers["Content-Disposition"] = v.(string)
+	}
+	if v, ok := d.GetOk("content_encoding"); ok {
+		headers["Content-Encoding"] = v.(string)
+	}
+	if v, ok := d.GetOk("content_type"); ok {
+		headers["Content-Type"] = v.(string)
+	}
+	// delete_after is only sent when it changes, otherwise the time it was set at is kept in delete_at
+	if d.HasChange("delete_after") {
+		if v, ok := d.GetOk("delete_after"); ok {
+			headers["X-Delete-After"] = strconv.Itoa(v.(int))
+		}
+	} else if v, ok := d.GetOk("delete_at"); ok {
+		headers["X-Delete-At"] = strconv.Itoa(v.(int))
+	}
+	if v, ok := d.GetOk("metadata"); ok {
+		for name, value := range v.(map[string]interface{}) {
+			headers["X-Object-Meta-"+name] = value.(string)
+		}
+	}
+	return headers
+}
+
 func resourceOPCStorageObjectDelete(d *schema.ResourceData, meta interface{}) error {
+	if _, ok := d.GetOk("segment_size"); ok {
+		storageClient, err := meta.(*Client).getStorageAPIClient()
+		if err != nil {
+			return err
+		}
+		container, name := parseStorageObjectID(d.Id())
+		if err := storageClient.DeleteSegmented(container, name, d.Get("manifest_type").(string), d.Get("object_manifest").(string)); err != nil {
+			return fmt.Errorf("Error deleting Storage Container Object (%s): %s", d.Id(), err)
+		}
+		return nil
+	}
+
 	storageClient, err := meta.(*Client).getStorageClient()
 	if err != nil {
 		return err
 	}
 	resClient := storageClient.Objects()
 
+	container, name := parseStorageObjectID(d.Id())
 	input := &storage.DeleteObjectInput{
-		ID: d.Id(),
+		Container: container,
+		Name:      name,
 	}
 	if err := resClient.DeleteObject(input); err != nil {
 		return fmt.Errorf("Error deleting Storage Container Object (%s): %s", d.Id(), err)
@@ -285,3 +419,148 @@ func resourceOPCStorageObjectDelete(d *schema.ResourceData, meta interface{}) er
 
 	return nil
 }
+
+// Splits the ID of a storage object, container/name, into its parts. Unlike the storage client,
+// this allows the object name to contain slashes, e.g. builds/1.0.0/app.tar.gz
+func parseStorageObjectID(id string) (string, string) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 {
+		return id, ""
+	}
+	return parts[0], parts[1]
+}
+
+// Uploads the file in segments, which are joined by a static or dynamic large object manifest
+func resourceOPCStorageObjectCreateSegmented(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	source, ok := d.GetOk("file")
+	if !ok {
+		return fmt.Errorf("`file` must be specified to upload a Storage Object in segments")
+	}
+	path, err := homedir.Expand(source.(string))
+	if err != nil {
+		return fmt.Errorf("Error expanding homedir in file (%s): %s", source, err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Error opening Storage Object file (%s): %s", source, err)
+	}
+	defer file.Close()
+
+	input := &storageSegmentedUploadInput{
+		Container:        d.Get("container").(string),
+		Name:             d.Get("name").(string),
+		SegmentContainer: d.Get("segment_container").(string),
+		SegmentSize:      int64(d.Get("segment_size").(int)),
+		Parallelism:      d.Get("segment_parallelism").(int),
+		ManifestType:     d.Get("manifest_type").(string),
+	}
+	if input.SegmentContainer == "" {
+		input.SegmentContainer = fmt.Sprintf("%s_segments", input.Container)
+	}
+
+	input.Headers = storageObjectHeaders(d)
+
+	if err := storageClient.UploadSegmented(input, file); err != nil {
+		return fmt.Errorf("Error creating Object: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", input.Container, input.Name))
+	d.Set("segment_container", input.SegmentContainer)
+	return resourceOPCStorageObjectRead(d, meta)
+}
+
+func validateStorageSegmentSize(v interface{}, k string) (ws []string, errors []error) {
+	size := int64(v.(int))
+	if size < storageMinSegmentSize || size > storageMaxSegmentSize {
+		errors = append(errors, fmt.Errorf("%q must be between %d and %d bytes, got: %d", k, storageMinSegmentSize, int64(storageMaxSegmentSize), size))
+	}
+	return
+}
+
+// Checks the file can be uploaded in segment_size segments, and replaces the object when the content of
+// the local file no longer matches the etag of the object, as only the path of the file is stored in the state
+func resourceOPCStorageObjectCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	source, ok := diff.GetOk("file")
+	if !ok {
+		return nil
+	}
+
+	// Files which don't exist yet, e.g. as they're created by another resource, are checked when they're uploaded
+	if segmentSize, ok := diff.GetOk("segment_size"); ok {
+		if path, err := homedir.Expand(source.(string)); err == nil {
+			if info, err := os.Stat(path); err == nil {
+				if err := checkStorageSegmentCount(info.Size(), int64(segmentSize.(int)), diff.Get("manifest_type").(string)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if diff.Id() == "" || diff.HasChange("file") || diff.HasChange("etag") {
+		return nil
+	}
+	etag := diff.Get("etag").(string)
+	if etag == "" {
+		return nil
+	}
+
+	local, err := localStorageObjectEtag(source.(string), int64(diff.Get("segment_size").(int)))
+	if err != nil {
+		log.Printf("[WARN] Unable to hash Storage Object file (%s), skipping drift detection: %s", source, err)
+		return nil
+	}
+	if local == etag {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Storage Object file (%s) has changed: etag %s, expected %s", source, local, etag)
+	if err := diff.SetNew("etag", local); err != nil {
+		return err
+	}
+	return diff.ForceNew("etag")
+}
+
+// Returns the etag the object will have once the file is uploaded. The etag of an object uploaded in
+// segments is the MD5 of the concatenated MD5s of the segments, for both static and dynamic large objects.
+func localStorageObjectEtag(source string, segmentSize int64) (string, error) {
+	path, err := homedir.Expand(source)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if segmentSize == 0 {
+		hash := md5.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	manifestHash := md5.New()
+	for i := 0; ; i++ {
+		hash := md5.New()
+		n, err := io.CopyN(hash, file, segmentSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		// An empty file is uploaded as a single, empty segment
+		if n == 0 && i > 0 {
+			break
+		}
+		manifestHash.Write([]byte(hex.EncodeToString(hash.Sum(nil))))
+		if n < segmentSize {
+			break
+		}
+	}
+	return hex.EncodeToString(manifestHash.Sum(nil)), nil
+}
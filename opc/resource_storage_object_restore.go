@@ -0,0 +1,132 @@
+package opc
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/client"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	storageRestoreInProgress = "in-progress"
+	storageRestoreCompleted  = "completed"
+)
+
+func resourceOPCStorageObjectRestore() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceOPCStorageObjectRestoreCreate,
+		Read:   resourceOPCStorageObjectRestoreRead,
+		Delete: resourceOPCStorageObjectRestoreDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		// Restoring an archived object can take up to four hours
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(4 * time.Hour),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"container": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"job_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceOPCStorageObjectRestoreCreate(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	container := d.Get("container").(string)
+	name := d.Get("name").(string)
+	id := fmt.Sprintf("%s/%s", container, name)
+
+	log.Printf("[DEBUG] Restoring storage object %s", id)
+	jobID, err := storageClient.RestoreObject(container, name)
+	if err != nil {
+		return fmt.Errorf("Error restoring storage object %s: %s", id, err)
+	}
+	d.SetId(id)
+	d.Set("job_id", jobID)
+
+	description := fmt.Sprintf("storage object %s to be restored", id)
+	err = meta.(*Client).waitFor(description, d.Timeout(schema.TimeoutCreate), func() (bool, error) {
+		headers, err := storageClient.GetObjectHeaders(container, name)
+		if err != nil {
+			return false, err
+		}
+		switch status := headers.Get("X-Archive-Restore-Status"); status {
+		case storageRestoreCompleted:
+			return true, nil
+		case storageRestoreInProgress:
+			return false, nil
+		case "":
+			// The status is set as soon as the restore starts, so objects without one aren't archived
+			return false, fmt.Errorf("the object has no restore status, check its container has the Archive storage class")
+		default:
+			return false, fmt.Errorf("unexpected restore status %q", status)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("Error waiting for storage object %s to be restored: %s", id, err)
+	}
+
+	return resourceOPCStorageObjectRestoreRead(d, meta)
+}
+
+func resourceOPCStorageObjectRestoreRead(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageAPIClient()
+	if err != nil {
+		return err
+	}
+
+	container, name := parseStorageObjectID(d.Id())
+	headers, err := storageClient.GetObjectHeaders(container, name)
+	if err != nil {
+		if client.WasNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading storage object %s: %s", d.Id(), err)
+	}
+
+	// Once the restored copy expires the object has to be restored again
+	status := headers.Get("X-Archive-Restore-Status")
+	if status != storageRestoreInProgress && status != storageRestoreCompleted {
+		log.Printf("[DEBUG] Storage object %s is no longer restored", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("container", container)
+	d.Set("name", name)
+	d.Set("status", status)
+
+	return nil
+}
+
+// A restore can't be undone, the restored copy expires by itself
+func resourceOPCStorageObjectRestoreDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,132 @@
+package opc
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-oracle-terraform/storage"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccOPCStorageObjectRestore_basic(t *testing.T) {
+	resName := "opc_storage_object_restore.test"
+	rInt := acctest.RandInt()
+	container := fmt.Sprintf("acc-test-archive-%d", rInt)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageContainerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObjectRestore_container(rInt, "Archive"),
+				Check:  resource.TestCheckResourceAttr("opc_storage_container.test", "storage_class", "Archive"),
+			},
+			{
+				// Archived objects can't be read until they're restored
+				PreConfig: testAccReplaceStorageObjectContent(t, container, "backup.txt", "archived"),
+				Config:    testAccOPCStorageObjectRestore_container(rInt, "Archive"),
+				Check:     testAccCheckStorageObjectArchived(container, "backup.txt"),
+			},
+			{
+				Config: testAccOPCStorageObjectRestore_basic(rInt, "Archive"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "status", "completed"),
+					resource.TestCheckResourceAttrSet(resName, "job_id"),
+				),
+			},
+			{
+				Config: testAccOPCStorageObjectRestore_read(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.opc_storage_object.test", "content", "archived"),
+				),
+			},
+			{
+				// The object is deleted, so the container can be destroyed
+				PreConfig: testAccDeleteStorageObject(t, container, "backup.txt"),
+				Config:    testAccOPCStorageObjectRestore_container(rInt, "Archive"),
+			},
+		},
+	})
+}
+
+func TestAccOPCStorageObjectRestore_notArchived(t *testing.T) {
+	rInt := acctest.RandInt()
+	container := fmt.Sprintf("acc-test-archive-%d", rInt)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageContainerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObjectRestore_container(rInt, "Standard"),
+			},
+			{
+				// Restoring an object which isn't archived fails straight away, rather than at the timeout
+				PreConfig:   testAccReplaceStorageObjectContent(t, container, "backup.txt", "standard"),
+				Config:      testAccOPCStorageObjectRestore_basic(rInt, "Standard"),
+				ExpectError: regexp.MustCompile("Error restoring storage object|has no restore status"),
+			},
+			{
+				PreConfig: testAccDeleteStorageObject(t, container, "backup.txt"),
+				Config:    testAccOPCStorageObjectRestore_container(rInt, "Standard"),
+			},
+		},
+	})
+}
+
+func testAccDeleteStorageObject(t *testing.T, container, name string) func() {
+	return func() {
+		client := testAccProvider.Meta().(*Client).storageAPIClient
+		if err := client.DeleteObject(container, name, nil); err != nil {
+			t.Fatalf("Error deleting %s/%s: %s", container, name, err)
+		}
+	}
+}
+
+func testAccCheckStorageObjectArchived(container, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client).storageAPIClient
+		object, err := client.GetObjectContent(&storage.GetObjectInput{Container: container, Name: name})
+		if err == nil {
+			object.Body.Close()
+			return fmt.Errorf("Expected reading archived storage object %s/%s to fail", container, name)
+		}
+		return nil
+	}
+}
+
+func testAccOPCStorageObjectRestore_container(rInt int, storageClass string) string {
+	return fmt.Sprintf(`
+resource "opc_storage_container" "test" {
+  name          = "acc-test-archive-%d"
+  storage_class = "%s"
+}
+`, rInt, storageClass)
+}
+
+func testAccOPCStorageObjectRestore_basic(rInt int, storageClass string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "opc_storage_object_restore" "test" {
+  container = "${opc_storage_container.test.name}"
+  name      = "backup.txt"
+}
+`, testAccOPCStorageObjectRestore_container(rInt, storageClass))
+}
+
+func testAccOPCStorageObjectRestore_read(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "opc_storage_object" "test" {
+  container = "${opc_storage_object_restore.test.container}"
+  name      = "${opc_storage_object_restore.test.name}"
+}
+`, testAccOPCStorageObjectRestore_basic(rInt, "Archive"))
+}
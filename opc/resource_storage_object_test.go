@@ -1,8 +1,16 @@
 package opc
 
 import (
+	"bytes"
+	"crypto/md5"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-oracle-terraform/storage"
 	"github.com/hashicorp/terraform/helper/acctest"
@@ -99,6 +107,199 @@ func TestAccOPCStorageObject_fileSource(t *testing.T) {
 	})
 }
 
+func TestAccOPCStorageObject_segmented(t *testing.T) {
+	staticName := "opc_storage_object.static"
+	dynamicName := "opc_storage_object.dynamic"
+	rInt := acctest.RandInt()
+
+	// Two and a half segments
+	file, err := ioutil.TempFile("", "opc-storage-object-segmented")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(bytes.Repeat([]byte("0123456789"), storageMinSegmentSize/4)); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_segmented(rInt, file.Name(), "application/octet-stream"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(staticName, "content_length", "2621440"),
+					resource.TestCheckResourceAttr(staticName, "content_type", "application/octet-stream"),
+					resource.TestCheckResourceAttr(staticName, "segment_container", fmt.Sprintf("acc-test-%d_segments", rInt)),
+					resource.TestCheckResourceAttr(staticName, "object_manifest", ""),
+					resource.TestCheckResourceAttr(dynamicName, "content_length", "2621440"),
+					resource.TestCheckResourceAttr(dynamicName, "segment_container", fmt.Sprintf("acc-test-segments-%d", rInt)),
+					resource.TestCheckResourceAttr(dynamicName, "object_manifest",
+						fmt.Sprintf("acc-test-segments-%d/dynamic.bin/2621440/1048576/", rInt)),
+				),
+			},
+			{
+				// The manifest is kept when the metadata is updated in place
+				Config: testAccOPCStorageObject_segmented(rInt, file.Name(), "application/x-tar"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dynamicName, "content_type", "application/x-tar"),
+					resource.TestCheckResourceAttr(dynamicName, "content_length", "2621440"),
+					resource.TestCheckResourceAttr(dynamicName, "object_manifest",
+						fmt.Sprintf("acc-test-segments-%d/dynamic.bin/2621440/1048576/", rInt)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCStorageObject_fileDrift(t *testing.T) {
+	resName := "opc_storage_object.test"
+	segmentedName := "opc_storage_object.segmented"
+	rInt := acctest.RandInt()
+
+	dir, err := ioutil.TempDir("", "opc-storage-object-drift")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.txt")
+	writeFile := func(content []byte) {
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	original := bytes.Repeat([]byte("a"), storageMinSegmentSize+1)
+	changed := bytes.Repeat([]byte("b"), storageMinSegmentSize+1)
+	writeFile(original)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_fileDrift(rInt, path),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "etag", fmt.Sprintf("%x", md5.Sum(original))),
+					testAccCheckStorageObjectLocalEtag(segmentedName, path),
+				),
+			},
+			{
+				PreConfig: func() { writeFile(changed) },
+				Config:    testAccOPCStorageObject_fileDrift(rInt, path),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "etag", fmt.Sprintf("%x", md5.Sum(changed))),
+					resource.TestCheckResourceAttr(resName, "content_length", "1048577"),
+					testAccCheckStorageObjectLocalEtag(segmentedName, path),
+				),
+			},
+		},
+	})
+}
+
+// Checks the etag of a segmented object matches the etag calculated from the local file
+func testAccCheckStorageObjectLocalEtag(resName, path string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resName]
+		if !ok {
+			return fmt.Errorf("Resource %s not found", resName)
+		}
+		expected, err := localStorageObjectEtag(path, storageMinSegmentSize)
+		if err != nil {
+			return err
+		}
+		if rs.Primary.Attributes["etag"] != expected {
+			return fmt.Errorf("Expected etag %s, got %s", expected, rs.Primary.Attributes["etag"])
+		}
+		return nil
+	}
+}
+
+func TestAccOPCStorageObject_updateMetadata(t *testing.T) {
+	resName := "opc_storage_object.test"
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_updateMetadata(rInt, "text/plain", "max-age=60"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "content_type", "text/plain"),
+					resource.TestCheckResourceAttr(resName, "metadata.%", "1"),
+					resource.TestCheckResourceAttr(resName, "metadata.Cache-Control", "max-age=60"),
+				),
+			},
+			{
+				// Replace the content behind Terraform's back, so re-uploading it would be noticed
+				PreConfig: testAccReplaceStorageObjectContent(t, fmt.Sprintf("acc-test-%d", rInt), "test.html", "changed"),
+				Config:    testAccOPCStorageObject_updateMetadata(rInt, "text/html", "max-age=3600"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "content_type", "text/html"),
+					resource.TestCheckResourceAttr(resName, "content_disposition", "inline"),
+					resource.TestCheckResourceAttr(resName, "metadata.%", "1"),
+					resource.TestCheckResourceAttr(resName, "metadata.Cache-Control", "max-age=3600"),
+					resource.TestCheckResourceAttr(resName, "etag", fmt.Sprintf("%x", md5.Sum([]byte("changed")))),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCStorageObject_deleteAfter(t *testing.T) {
+	resName := "opc_storage_object.test"
+	rInt := acctest.RandInt()
+	var deleteAt string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_deleteAfter(rInt, "bar"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "delete_after", "3600"),
+					func(s *terraform.State) error {
+						deleteAt = s.RootModule().Resources[resName].Primary.Attributes["delete_at"]
+						expected := time.Now().Add(time.Hour).Unix()
+						if v, _ := strconv.ParseInt(deleteAt, 10, 64); v < expected-60 || v > expected {
+							return fmt.Errorf("Expected delete_at to be an hour from now, got %s", deleteAt)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// The object is still deleted at the same time when other attributes change
+				PreConfig: func() { time.Sleep(time.Second) },
+				Config:    testAccOPCStorageObject_deleteAfter(rInt, "baz"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "metadata.Foo", "baz"),
+					func(s *terraform.State) error {
+						return resource.TestCheckResourceAttr(resName, "delete_at", deleteAt)(s)
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccReplaceStorageObjectContent(t *testing.T, container, name, content string) func() {
+	return func() {
+		client := testAccProvider.Meta().(*Client).storageAPIClient
+		if _, err := client.PutObject(container, name, nil, strings.NewReader(content)); err != nil {
+			t.Fatalf("Error replacing the content of %s/%s: %s", container, name, err)
+		}
+	}
+}
+
 func TestAccOPCStorageObject_objectMetadata(t *testing.T) {
 	resName := "opc_storage_object.test"
 	rInt := acctest.RandInt()
@@ -133,11 +334,13 @@ func testAccCheckStorageObjectExists(s *terraform.State) error {
 			continue
 		}
 
+		container, name := parseStorageObjectID(rs.Primary.Attributes["id"])
 		input := &storage.GetObjectInput{
-			ID: rs.Primary.Attributes["id"],
+			Container: container,
+			Name:      name,
 		}
 		if _, err := client.GetObject(input); err != nil {
-			return fmt.Errorf("Error retrieving state of Storage Object (%s): %s", input.ID, err)
+			return fmt.Errorf("Error retrieving state of Storage Object (%s): %s", rs.Primary.ID, err)
 		}
 	}
 	return nil
@@ -151,12 +354,14 @@ func testAccCheckStorageObjectDestroy(s *terraform.State) error {
 			continue
 		}
 
+		container, name := parseStorageObjectID(rs.Primary.Attributes["id"])
 		input := &storage.GetObjectInput{
-			ID: rs.Primary.Attributes["id"],
+			Container: container,
+			Name:      name,
 		}
 
 		if info, err := client.GetObject(input); err == nil {
-			return fmt.Errorf("Storage Object (%s) still exists: %#v", input.ID, info)
+			return fmt.Errorf("Storage Object (%s) still exists: %#v", rs.Primary.ID, info)
 		}
 	}
 	return nil
@@ -226,6 +431,87 @@ EOF
 		body)
 }
 
+func testAccOPCStorageObject_segmented(rInt int, path, contentType string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "opc_storage_container" "segments" {
+  name = "acc-test-segments-%d"
+}
+
+resource "opc_storage_object" "static" {
+  name                = "static.bin"
+  container           = "${opc_storage_container.foo.name}"
+  content_type        = "application/octet-stream"
+  file                = "%s"
+  segment_size        = 1048576
+  segment_parallelism = 2
+}
+
+resource "opc_storage_object" "dynamic" {
+  name              = "dynamic.bin"
+  container         = "${opc_storage_container.foo.name}"
+  content_type      = "%s"
+  file              = "%s"
+  segment_size      = 1048576
+  segment_container = "${opc_storage_container.segments.name}"
+  manifest_type     = "dynamic"
+}`, testAccOPCStorageObject_testContainer(rInt), rInt, path, contentType, path)
+}
+
+func testAccOPCStorageObject_fileDrift(rInt int, path string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "opc_storage_object" "test" {
+  name      = "config.txt"
+  container = "${opc_storage_container.foo.name}"
+  file      = "%s"
+}
+
+resource "opc_storage_object" "segmented" {
+  name         = "segmented.txt"
+  container    = "${opc_storage_container.foo.name}"
+  file         = "%s"
+  segment_size = 1048576
+}`, testAccOPCStorageObject_testContainer(rInt), path, path)
+}
+
+func testAccOPCStorageObject_updateMetadata(rInt int, contentType, cacheControl string) string {
+	disposition := ""
+	if contentType == "text/html" {
+		disposition = "content_disposition = \"inline\""
+	}
+	return fmt.Sprintf(`
+%s
+
+resource "opc_storage_object" "test" {
+  name         = "test.html"
+  container    = "${opc_storage_container.foo.name}"
+  content      = "original"
+  content_type = "%s"
+  %s
+  metadata = {
+    Cache-Control = "%s"
+  }
+}`, testAccOPCStorageObject_testContainer(rInt), contentType, disposition, cacheControl)
+}
+
+func testAccOPCStorageObject_deleteAfter(rInt int, foo string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "opc_storage_object" "test" {
+  name         = "test.txt"
+  container    = "${opc_storage_container.foo.name}"
+  content      = "temporary"
+  delete_after = 3600
+  metadata = {
+    Foo = "%s"
+  }
+}`, testAccOPCStorageObject_testContainer(rInt), foo)
+}
+
 func testAccOPCStorageObject_fileSource(rInt int, path string) string {
 	return fmt.Sprintf(`
 %s
@@ -263,3 +549,16 @@ eleifend id, tincidunt eget dolor. Nam pulvinar, dui non pellentesque dignissim,
 nisi nunc vel turpis. Vivamus eget dapibus lacus. Mauris convallis mi sit amet faucibus placerat. Mauris gravida neque
 tortor, vel placerat sem elementum venenatis. Integer eu placerat est. Sed sem massa, volutpat eget augue eget, aliquam
 semper sem.`
+
+func TestParseStorageObjectID(t *testing.T) {
+	cases := map[string][2]string{
+		"acc-test/app.tar.gz":              {"acc-test", "app.tar.gz"},
+		"acc-test/builds/1.0.0/app.tar.gz": {"acc-test", "builds/1.0.0/app.tar.gz"},
+		"acc-test":                         {"acc-test", ""},
+	}
+	for id, expected := range cases {
+		if container, name := parseStorageObjectID(id); container != expected[0] || name != expected[1] {
+			t.Errorf("Expected %s to be split into %q and %q, got %q and %q", id, expected[0], expected[1], container, name)
+		}
+	}
+}
@@ -138,7 +138,7 @@ func resourceOPCStorageVolume() *schema.Resource {
 }
 
 func resourceOPCStorageVolumeCreate(d *schema.ResourceData, meta interface{}) error {
-	computeClient, err := meta.(*Client).getComputeClient()
+	computeClient, err := meta.(*Client).getComputeCreateClient()
 	if err != nil {
 		return err
 	}
@@ -162,6 +162,7 @@ func resourceOPCStorageVolumeCreate(d *schema.ResourceData, meta interface{}) er
 		ImageListEntry: imageListEntry,
 		Tags:           getStringList(d, "tags"),
 		Timeout:        d.Timeout(schema.TimeoutCreate),
+		PollInterval:   meta.(*Client).pollInterval,
 	}
 
 	if v, ok := d.GetOk("snapshot"); ok {
@@ -206,8 +207,12 @@ func resourceOPCStorageVolumeUpdate(d *schema.ResourceData, meta interface{}) er
 		ImageListEntry: imageListEntry,
 		Tags:           getStringList(d, "tags"),
 		Timeout:        d.Timeout(schema.TimeoutUpdate),
+		PollInterval:   meta.(*Client).pollInterval,
 	}
-	_, err = resClient.UpdateStorageVolume(&input)
+	err = meta.(*Client).interruptible(fmt.Sprintf("storage volume %s to be updated", name), func() error {
+		_, err := resClient.UpdateStorageVolume(&input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating storage volume %s: %s", name, err)
 	}
@@ -277,10 +282,13 @@ func resourceOPCStorageVolumeDelete(d *schema.ResourceData, meta interface{}) er
 	name := d.Id()
 
 	input := compute.DeleteStorageVolumeInput{
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
+		Name:         name,
+		Timeout:      d.Timeout(schema.TimeoutDelete),
+		PollInterval: meta.(*Client).pollInterval,
 	}
-	err = resClient.DeleteStorageVolume(&input)
+	err = meta.(*Client).interruptible(fmt.Sprintf("storage volume %s to be deleted", name), func() error {
+		return resClient.DeleteStorageVolume(&input)
+	})
 	if err != nil {
 		return fmt.Errorf("Error deleting storage volume %s: %s", name, err)
 	}
@@ -128,7 +128,7 @@ func resourceOPCStorageVolumeSnapshot() *schema.Resource {
 }
 
 func resourceOPCStorageVolumeSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
-	computeClient, err := meta.(*Client).getComputeClient()
+	computeClient, err := meta.(*Client).getComputeCreateClient()
 	if err != nil {
 		return err
 	}
@@ -136,8 +136,9 @@ func resourceOPCStorageVolumeSnapshotCreate(d *schema.ResourceData, meta interfa
 
 	// Get required attribute
 	input := &compute.CreateStorageVolumeSnapshotInput{
-		Volume:  d.Get("volume_name").(string),
-		Timeout: d.Timeout(schema.TimeoutCreate),
+		Volume:       d.Get("volume_name").(string),
+		Timeout:      d.Timeout(schema.TimeoutCreate),
+		PollInterval: meta.(*Client).pollInterval,
 	}
 
 	if v, ok := d.GetOk("description"); ok {
@@ -245,11 +246,15 @@ func resourceOPCStorageVolumeSnapshotDelete(d *schema.ResourceData, meta interfa
 	name := d.Id()
 
 	input := &compute.DeleteStorageVolumeSnapshotInput{
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
+		Name:         name,
+		Timeout:      d.Timeout(schema.TimeoutDelete),
+		PollInterval: meta.(*Client).pollInterval,
 	}
 
-	if err := resClient.DeleteStorageVolumeSnapshot(input); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("storage volume snapshot %s to be deleted", name), func() error {
+		return resClient.DeleteStorageVolumeSnapshot(input)
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting storage volume snapshot '%s': %v", name, err)
 	}
 
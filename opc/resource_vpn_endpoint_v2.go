@@ -153,7 +153,7 @@ func resourceOPCVPNEndpointV2Create(d *schema.ResourceData, meta interface{}) er
 
 	log.Print("[DEBUG] Creating VPNEndpointV2")
 
-	computeClient, err := meta.(*Client).getComputeClient()
+	computeClient, err := meta.(*Client).getComputeCreateClient()
 	if err != nil {
 		return err
 	}
@@ -166,6 +166,7 @@ func resourceOPCVPNEndpointV2Create(d *schema.ResourceData, meta interface{}) er
 		PSK:                d.Get("pre_shared_key").(string),
 		ReachableRoutes:    getStringList(d, "reachable_routes"),
 		VNICSets:           getStringList(d, "vnic_sets"),
+		PollInterval:       meta.(*Client).pollInterval,
 	}
 
 	tags := getStringList(d, "tags")
@@ -285,6 +286,7 @@ func resourceOPCVPNEndpointV2Update(d *schema.ResourceData, meta interface{}) er
 		IPNetwork:          d.Get("ip_network").(string),
 		PSK:                d.Get("pre_shared_key").(string),
 		VNICSets:           getStringList(d, "vnic_sets"),
+		PollInterval:       meta.(*Client).pollInterval,
 	}
 
 	tags := getStringList(d, "tags")
@@ -316,7 +318,11 @@ func resourceOPCVPNEndpointV2Update(d *schema.ResourceData, meta interface{}) er
 		input.Phase2Settings = nil
 	}
 
-	info, err := resClient.UpdateVPNEndpointV2(&input)
+	var info *compute.VPNEndpointV2Info
+	err = meta.(*Client).interruptible(fmt.Sprintf("VPN endpoint %s to be updated", input.Name), func() (err error) {
+		info, err = resClient.UpdateVPNEndpointV2(&input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating VPNEndpointV2: %s", err)
 	}
@@ -337,9 +343,13 @@ func resourceOPCVPNEndpointV2Delete(d *schema.ResourceData, meta interface{}) er
 	log.Printf("[DEBUG] Deleting VPNEndpointV2: %v", name)
 
 	input := compute.DeleteVPNEndpointV2Input{
-		Name: name,
+		Name:         name,
+		PollInterval: meta.(*Client).pollInterval,
 	}
-	if err := resClient.DeleteVPNEndpointV2(&input); err != nil {
+	err = meta.(*Client).interruptible(fmt.Sprintf("VPN endpoint %s to be deleted", input.Name), func() error {
+		return resClient.DeleteVPNEndpointV2(&input)
+	})
+	if err != nil {
 		return fmt.Errorf("Error deleting VPNEndpointV2")
 	}
 	return nil
@@ -0,0 +1,185 @@
+package opc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status codes that are retried when the provider configuration doesn't specify any
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+const (
+	defaultRetryMaxElapsedTime = 5 * time.Minute
+	defaultRetryBaseBackoff    = 1 * time.Second
+	defaultRetryMaxBackoff     = 60 * time.Second
+)
+
+// retryPolicy decides which API responses are transient, and how long to wait between attempts
+type retryPolicy struct {
+	// The number of times a transient error is retried, after the first attempt
+	MaxRetries           int
+	RetryableStatusCodes []int
+	MaxElapsedTime       time.Duration
+	BaseBackoff          time.Duration
+	MaxBackoff           time.Duration
+}
+
+// Returns whether the response, or transport error, is worth trying again.
+// Conflicts are only transient when the API reports the object as being in use,
+// e.g. a storage volume that is still detaching from an instance.
+func (p *retryPolicy) isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return conflictIsObjectInUse(resp)
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the wait before the given attempt, doubling from the base backoff with jitter,
+// up to the maximum backoff. A Retry-After header from the API takes precedence.
+func (p *retryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp); ok {
+		return wait
+	}
+	wait := p.BaseBackoff
+	for i := 1; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > 0 {
+		wait += time.Duration(rand.Int63n(int64(wait))) / 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// retryTransport retries transient API errors according to a retryPolicy
+type retryTransport struct {
+	policy    retryPolicy
+	transport http.RoundTripper
+	// Requests made without a context of their own, as the API clients' are, are cancelled
+	// with this context
+	stop context.Context
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.stop != nil && req.Context().Done() == nil && req.Context().Value(uninterruptibleKey{}) == nil {
+		req = req.WithContext(t.stop)
+	}
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		resp, err := t.transport.RoundTrip(withRetryAttempt(req, attempt))
+		if !t.policy.isRetryable(resp, err) || attempt > t.policy.MaxRetries {
+			return resp, err
+		}
+		// Streamed request bodies, such as storage object uploads, can't be sent again
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := t.policy.backoff(attempt, resp)
+		if time.Since(start)+wait > t.policy.MaxElapsedTime {
+			log.Printf("[DEBUG] Not retrying %s %s, next attempt would exceed the maximum elapsed time of %s", req.Method, req.URL, t.policy.MaxElapsedTime)
+			return resp, err
+		}
+
+		if err != nil {
+			log.Printf("[DEBUG] %s %s failed (attempt %d of %d), retrying in %s: %s", req.Method, req.URL, attempt, t.policy.MaxRetries+1, wait, err)
+		} else {
+			log.Printf("[DEBUG] %s %s returned HTTP %d (attempt %d of %d), retrying in %s", req.Method, req.URL, resp.StatusCode, attempt, t.policy.MaxRetries+1, wait)
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// Reads the body of a conflict response to check whether the object is in use,
+// leaving the body in place for the caller
+func conflictIsObjectInUse(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(body)), "in use")
+}
+
+// Parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+type uninterruptibleKey struct{}
+
+// Marks the request as one the retryTransport doesn't cancel when Terraform is interrupted
+func uninterruptible(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), uninterruptibleKey{}, true))
+}
+
+// uninterruptibleTransport marks every request as uninterruptible. The API clients using it create
+// resources, whose requests are left to finish so that the resource is recorded in the state.
+type uninterruptibleTransport struct {
+	transport http.RoundTripper
+}
+
+func (t *uninterruptibleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(uninterruptible(req))
+}
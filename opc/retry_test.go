@@ -0,0 +1,147 @@
+package opc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() retryPolicy {
+	return retryPolicy{
+		MaxRetries:           2,
+		RetryableStatusCodes: defaultRetryableStatusCodes,
+		MaxElapsedTime:       time.Minute,
+		BaseBackoff:          time.Millisecond,
+		MaxBackoff:           10 * time.Millisecond,
+	}
+}
+
+// Returns a server that replies with each of the responses in turn, and a count of requests made
+func testRetryServer(t *testing.T, statuses []int, body string) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			if b, _ := ioutil.ReadAll(r.Body); string(b) != "request body" && len(b) != 0 {
+				t.Errorf("Expected the request body to be resent, got %q", b)
+			}
+		}
+		status := statuses[len(statuses)-1]
+		if requests < len(statuses) {
+			status = statuses[requests]
+		}
+		requests++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	return server, &requests
+}
+
+func TestRetryTransport(t *testing.T) {
+	cases := []struct {
+		Name             string
+		Statuses         []int
+		Body             string
+		ExpectedStatus   int
+		ExpectedRequests int
+	}{
+		{"success", []int{200}, "", 200, 1},
+		{"transient", []int{503, 502, 200}, "", 200, 3},
+		{"throttled", []int{429, 200}, "", 200, 2},
+		{"exhausted", []int{500}, "", 500, 3},
+		{"validation", []int{400}, "Invalid shape", 400, 1},
+		{"not found", []int{404}, "", 404, 1},
+		{"object in use", []int{409, 200}, `{"message": "Volume is in use"}`, 200, 2},
+		{"conflict", []int{409}, `{"message": "Object already exists"}`, 409, 1},
+	}
+
+	for _, tc := range cases {
+		server, requests := testRetryServer(t, tc.Statuses, tc.Body)
+		client := &http.Client{Transport: &retryTransport{
+			policy:    testRetryPolicy(),
+			transport: http.DefaultTransport,
+		}}
+
+		resp, err := client.Post(server.URL, "text/plain", bytes.NewReader([]byte("request body")))
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.Name, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.ExpectedStatus {
+			t.Fatalf("%s: expected status %d, got %d", tc.Name, tc.ExpectedStatus, resp.StatusCode)
+		}
+		if *requests != tc.ExpectedRequests {
+			t.Fatalf("%s: expected %d requests, got %d", tc.Name, tc.ExpectedRequests, *requests)
+		}
+		if resp.StatusCode != 200 && string(body) != tc.Body {
+			t.Fatalf("%s: expected the error body %q to be returned, got %q", tc.Name, tc.Body, body)
+		}
+	}
+}
+
+func TestRetryTransport_maxElapsedTime(t *testing.T) {
+	server, requests := testRetryServer(t, []int{503}, "")
+	defer server.Close()
+
+	policy := testRetryPolicy()
+	policy.BaseBackoff = time.Second
+	policy.MaxBackoff = time.Second
+	policy.MaxElapsedTime = 100 * time.Millisecond
+	client := &http.Client{Transport: &retryTransport{policy: policy, transport: http.DefaultTransport}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if *requests != 1 {
+		t.Fatalf("Expected a single request when the backoff exceeds the maximum elapsed time, got %d", *requests)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := testRetryPolicy()
+	policy.BaseBackoff = time.Second
+	policy.MaxBackoff = 5 * time.Second
+
+	for attempt, min := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		wait := policy.backoff(attempt+1, nil)
+		if wait < min || wait > policy.MaxBackoff {
+			t.Fatalf("Attempt %d: expected a backoff between %s and %s, got %s", attempt+1, min, policy.MaxBackoff, wait)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "30")
+	if wait := policy.backoff(1, resp); wait != 30*time.Second {
+		t.Fatalf("Expected the Retry-After header to be honoured, got %s", wait)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if wait := policy.backoff(1, resp); wait != 0 {
+		t.Fatalf("Expected a Retry-After date in the past not to wait, got %s", wait)
+	}
+}
+
+func TestRetryTransport_defaultMaxRetries(t *testing.T) {
+	server, requests := testRetryServer(t, []int{503, 200}, "")
+	defer server.Close()
+
+	// The provider's default max_retries retries a transient error once
+	policy := (&Config{MaxRetries: 1, RetryBaseBackoff: time.Millisecond}).retryPolicy()
+	client := &http.Client{Transport: &retryTransport{policy: policy, transport: http.DefaultTransport}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 || *requests != 2 {
+		t.Fatalf("Expected the 503 to be retried once, got HTTP %d after %d requests", resp.StatusCode, *requests)
+	}
+}
@@ -0,0 +1,275 @@
+package opc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials are refreshed after the same interval the API clients use, ahead of the
+// 30 minute expiry of compute cookies and storage tokens
+const sessionLifetime = 25 * time.Minute
+
+// sessionAuthenticator describes how a service authenticates and how its credentials are sent
+type sessionAuthenticator interface {
+	// Returns whether the request is an authentication request made by an API client
+	isAuthRequest(req *http.Request) bool
+	// Builds a new authentication request for the service
+	authRequest() (*http.Request, error)
+	// Checks the headers of an authentication response contain a credential
+	validate(header http.Header) error
+	// Replaces the credential on an API request with the one from the session
+	apply(req *http.Request, header http.Header)
+}
+
+// apiSession holds the authentication for a single service, shared between every API client
+// and sub-client for that service. The go-oracle-terraform clients each track their own
+// credentials, so the session transport overrides them on every request.
+type apiSession struct {
+	mu     sync.Mutex
+	name   string
+	auth   sessionAuthenticator
+	header http.Header
+	issued time.Time
+}
+
+// sessionTransport authenticates requests using a shared apiSession, re-authenticating and
+// retrying once if the API rejects the session's credentials
+type sessionTransport struct {
+	session   *apiSession
+	transport http.RoundTripper
+}
+
+func newSessionTransport(name string, auth sessionAuthenticator, transport http.RoundTripper) *sessionTransport {
+	return &sessionTransport{
+		session:   &apiSession{name: name, auth: auth},
+		transport: transport,
+	}
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.session.auth.isAuthRequest(req) {
+		return t.authenticateFor(req)
+	}
+
+	header, err := t.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	authed := req.Clone(req.Context())
+	t.session.auth.apply(authed, header)
+	resp, err := t.transport.RoundTrip(authed)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	// The 401 is never returned to the API clients, as they re-authenticate by themselves without
+	// any locking, which isn't safe when the clients are shared between resources
+	log.Printf("[DEBUG] %s session was rejected for %s %s, re-authenticating", t.session.name, req.Method, req.URL)
+	header, err = t.refresh(header)
+	if err != nil {
+		return nil, fmt.Errorf("The %s session was rejected for %s %s: %s", t.session.name, req.Method, req.URL, err)
+	}
+	// Streamed request bodies can't be sent again, but the next request uses the new session
+	if req.Body != nil && req.GetBody == nil {
+		return nil, fmt.Errorf("The %s session was rejected for %s %s, which can't be sent again as its body is streamed", t.session.name, req.Method, req.URL)
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	t.session.auth.apply(retry, header)
+	return t.transport.RoundTrip(retry)
+}
+
+// Answers an API client's own authentication request with the session's credentials, so concurrent
+// clients don't each re-authenticate. If re-authenticating fails, the client is still given the
+// previous credentials rather than an error, as the clients clear their credentials before
+// re-authenticating, without any locking, and each request is sent with the session's credentials
+// anyway. Only the first authentication can fail.
+func (t *sessionTransport) authenticateFor(req *http.Request) (*http.Response, error) {
+	t.session.mu.Lock()
+	defer t.session.mu.Unlock()
+
+	if t.session.header == nil || time.Since(t.session.issued) >= sessionLifetime {
+		if err := t.authenticate(); err != nil {
+			if t.session.header == nil {
+				return nil, err
+			}
+			log.Printf("[WARN] %s, answering the %s client with the previous session", err, t.session.name)
+		}
+	}
+
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     t.session.header.Clone(),
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// Returns the session's credentials, authenticating first if they are missing or have expired
+func (t *sessionTransport) credentials() (http.Header, error) {
+	t.session.mu.Lock()
+	defer t.session.mu.Unlock()
+
+	if t.session.header != nil && time.Since(t.session.issued) < sessionLifetime {
+		return t.session.header, nil
+	}
+	if err := t.authenticate(); err != nil {
+		return nil, err
+	}
+	return t.session.header, nil
+}
+
+// Re-authenticates after the stale credentials were rejected, unless another request has
+// already done so
+func (t *sessionTransport) refresh(stale http.Header) (http.Header, error) {
+	t.session.mu.Lock()
+	defer t.session.mu.Unlock()
+
+	if t.session.header != nil && time.Since(t.session.issued) < sessionLifetime && !sameHeader(t.session.header, stale) {
+		return t.session.header, nil
+	}
+	if err := t.authenticate(); err != nil {
+		return nil, err
+	}
+	return t.session.header, nil
+}
+
+// Authenticates with the service. Must be called with the session lock held.
+func (t *sessionTransport) authenticate() error {
+	req, err := t.session.auth.authRequest()
+	if err != nil {
+		return err
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return fmt.Errorf("Error authenticating with the %s API: %s", t.session.name, err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("Error authenticating with the %s API: HTTP %d: %s", t.session.name, resp.StatusCode, body)
+	}
+	if err := t.session.auth.validate(resp.Header); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Authenticated %s session", t.session.name)
+	t.session.header = resp.Header.Clone()
+	t.session.issued = time.Now()
+	return nil
+}
+
+func sameHeader(a, b http.Header) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if strings.Join(a[k], ",") != strings.Join(b[k], ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// computeAuthenticator authenticates with the Compute Classic API using a session cookie
+type computeAuthenticator struct {
+	endpoint       *url.URL
+	identityDomain string
+	user           string
+	password       string
+	userAgent      string
+}
+
+func (a *computeAuthenticator) isAuthRequest(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/authenticate/")
+}
+
+func (a *computeAuthenticator) authRequest() (*http.Request, error) {
+	body, err := json.Marshal(map[string]string{
+		"user":     fmt.Sprintf("/Compute-%s/%s", a.identityDomain, a.user),
+		"password": a.password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	authURL := a.endpoint.ResolveReference(&url.URL{Path: "/authenticate/"})
+	req, err := http.NewRequest(http.MethodPost, authURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/oracle-compute-v3+json")
+	req.Header.Set("User-Agent", a.userAgent)
+	return req, nil
+}
+
+func (a *computeAuthenticator) validate(header http.Header) error {
+	if len((&http.Response{Header: header}).Cookies()) == 0 {
+		return fmt.Errorf("No authentication cookie found in the Compute API response")
+	}
+	return nil
+}
+
+func (a *computeAuthenticator) apply(req *http.Request, header http.Header) {
+	req.Header.Del("Cookie")
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		req.AddCookie(cookie)
+	}
+}
+
+// storageAuthenticator authenticates with the Object Storage Classic API using a token
+type storageAuthenticator struct {
+	endpoint  *url.URL
+	serviceID string
+	user      string
+	password  string
+	userAgent string
+}
+
+func (a *storageAuthenticator) isAuthRequest(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/auth/v1.0")
+}
+
+func (a *storageAuthenticator) authRequest() (*http.Request, error) {
+	authURL := a.endpoint.ResolveReference(&url.URL{Path: "/auth/v1.0"})
+	req, err := http.NewRequest(http.MethodGet, authURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Storage-User", fmt.Sprintf("Storage-%s:%s", a.serviceID, a.user))
+	req.Header.Set("X-Storage-Pass", a.password)
+	req.Header.Set("User-Agent", a.userAgent)
+	return req, nil
+}
+
+func (a *storageAuthenticator) validate(header http.Header) error {
+	if header.Get("X-Auth-Token") == "" {
+		return fmt.Errorf("No authentication token found in the Storage API response")
+	}
+	return nil
+}
+
+func (a *storageAuthenticator) apply(req *http.Request, header http.Header) {
+	req.Header.Set("X-Auth-Token", header.Get("X-Auth-Token"))
+}
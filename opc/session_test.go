@@ -0,0 +1,199 @@
+package opc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/go-oracle-terraform/storage"
+	"github.com/terraform-providers/terraform-provider-opc/opc/simulator"
+)
+
+func testSessionConfig(s *simulator.Server) *Config {
+	return &Config{
+		User:            s.User,
+		Password:        s.Password,
+		IdentityDomain:  s.IdentityDomain,
+		Endpoint:        s.ComputeEndpoint(),
+		StorageEndpoint: s.StorageEndpoint(),
+		MaxRetries:      1,
+	}
+}
+
+func TestSessionTransport_computeReauthentication(t *testing.T) {
+	s := simulator.New()
+	defer s.Close()
+
+	client, err := testSessionConfig(s).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secLists := client.computeClient.SecurityLists()
+	if _, err := secLists.CreateSecurityList(&compute.CreateSecurityListInput{Name: "before"}); err != nil {
+		t.Fatalf("Error creating security list: %s", err)
+	}
+
+	s.ExpireSessions()
+
+	// A sub-client created before the session expired uses the refreshed cookie
+	if _, err := secLists.CreateSecurityList(&compute.CreateSecurityListInput{Name: "after"}); err != nil {
+		t.Fatalf("Expected the request to succeed after re-authenticating, got: %s", err)
+	}
+}
+
+func TestSessionTransport_storageReauthentication(t *testing.T) {
+	s := simulator.New()
+	defer s.Close()
+
+	client, err := testSessionConfig(s).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.storageClient.CreateContainer(&storage.CreateContainerInput{Name: "container"}); err != nil {
+		t.Fatalf("Error creating container: %s", err)
+	}
+
+	s.ExpireSessions()
+
+	// Objects() copies the token of its parent client, which is no longer valid
+	_, err = client.storageClient.Objects().CreateObject(&storage.CreateObjectInput{
+		Name:      "object",
+		Container: "container",
+		Body:      bytes.NewReader([]byte("content")),
+	})
+	if err != nil {
+		t.Fatalf("Expected the request to succeed after re-authenticating, got: %s", err)
+	}
+	if _, err := client.storageClient.GetContainer(&storage.GetContainerInput{Name: "container"}); err != nil {
+		t.Fatalf("Error reading container: %s", err)
+	}
+}
+
+func TestSessionTransport_concurrentReauthentication(t *testing.T) {
+	var authentications, current int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/authenticate/" {
+			n := atomic.AddInt32(&authentications, 1)
+			atomic.StoreInt32(&current, n)
+			http.SetCookie(w, &http.Cookie{Name: "nimbula", Value: string(rune('a' + n))})
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		cookie, err := r.Cookie("nimbula")
+		if err != nil || cookie.Value != string(rune('a'+atomic.LoadInt32(&current))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := newSessionTransport("Compute", &computeAuthenticator{
+		endpoint:       endpoint,
+		identityDomain: "domain",
+		user:           "user",
+		password:       "password",
+	}, http.DefaultTransport)
+	httpClient := newHTTPClient(transport)
+
+	get := func() int {
+		resp, err := httpClient.Get(server.URL + "/instance/")
+		if err != nil {
+			t.Error(err)
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+
+	// Invalidate the session, then make several requests at once
+	atomic.StoreInt32(&current, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status := get(); status != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", status)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&authentications); n != 2 {
+		t.Fatalf("Expected a single re-authentication to be shared by every request, got %d authentications", n)
+	}
+}
+
+func TestSessionTransport_failedReauthentication(t *testing.T) {
+	var rejected int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&rejected) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/authenticate/" {
+			http.SetCookie(w, &http.Cookie{Name: "nimbula", Value: "session"})
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := newSessionTransport("Compute", &computeAuthenticator{
+		endpoint:       endpoint,
+		identityDomain: "domain",
+		user:           "user",
+		password:       "password",
+	}, http.DefaultTransport)
+	httpClient := newHTTPClient(transport)
+
+	authenticate := func() (*http.Response, error) {
+		return httpClient.Post(server.URL+"/authenticate/", "application/oracle-compute-v3+json", bytes.NewReader([]byte("{}")))
+	}
+	resp, err := authenticate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Once the session expires and the credentials are rejected, the API clients are still given
+	// the previous cookie, so they never clear theirs, and requests fail with an error rather than a 401
+	atomic.StoreInt32(&rejected, 1)
+	transport.session.issued = time.Now().Add(-sessionLifetime)
+
+	resp, err = authenticate()
+	if err != nil {
+		t.Fatalf("Expected the previous session to be returned, got %s", err)
+	}
+	resp.Body.Close()
+	if len(resp.Cookies()) != 1 || resp.Cookies()[0].Value != "session" {
+		t.Fatalf("Expected the previous session cookie, got %v", resp.Cookies())
+	}
+
+	resp, err = httpClient.Get(server.URL + "/instance/")
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("Expected an error once re-authenticating fails, got status %d", resp.StatusCode)
+	}
+}
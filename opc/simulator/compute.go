@@ -0,0 +1,811 @@
+package simulator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	computeContentType = "application/oracle-compute-v3+json"
+	computeCookieName  = "nimbula"
+	computeCookieTTL   = 30 * time.Minute
+)
+
+// Public objects that are expected to exist in every Compute Classic site
+var publicImageLists = []string{
+	"/oracle/public/OL_7.2_UEKR4_x86_64",
+	"/oracle/public/OL_6.8_UEKR4_x86_64",
+	"/oracle/public/oel_6.7_apaas_16.4.5_1610211300",
+	"/oracle/public/OL_5.11_UEKR2_i386-17.2.2-20170405-205607",
+	"/oracle/public/OL_5.11_UEKR2_x86_64",
+}
+
+// The general purpose and high memory shapes, with their number of CPUs and RAM in MB
+var shapes = []struct {
+	Name string
+	CPUs float64
+	RAM  int
+}{
+	{"oc3", 2, 7680},
+	{"oc4", 4, 15360},
+	{"oc5", 8, 30720},
+	{"oc6", 16, 61440},
+	{"oc7", 32, 122880},
+	{"oc1m", 2, 15360},
+	{"oc2m", 4, 30720},
+	{"oc3m", 8, 61440},
+	{"oc4m", 16, 122880},
+	{"oc5m", 32, 245760},
+}
+
+func (s *Server) seedCompute() {
+	for _, shape := range shapes {
+		s.compute["/shape/"+shape.Name] = map[string]interface{}{
+			"name":                   shape.Name,
+			"cpus":                   shape.CPUs,
+			"ram":                    shape.RAM,
+			"gpus":                   0,
+			"io":                     int(shape.CPUs) * 100,
+			"root_disk_size":         0,
+			"is_root_ssd":            false,
+			"ssd_data_size":          0,
+			"nds_iops_limit":         0,
+			"placement_requirements": []interface{}{},
+			"uri":                    "/shape/" + shape.Name,
+		}
+	}
+	for _, name := range publicImageLists {
+		s.compute["/imagelist"+name] = map[string]interface{}{
+			"name":        name,
+			"description": "Public image list",
+			"default":     1,
+			"uri":         "/imagelist" + name,
+		}
+		s.compute["/imagelist"+name+"/entry/1"] = map[string]interface{}{
+			"version":       1,
+			"machineimages": []interface{}{name},
+			"uri":           "/imagelist" + name + "/entry/1",
+		}
+		s.compute["/machineimage"+name] = map[string]interface{}{
+			"name":     name,
+			"account":  "/oracle/public/default",
+			"platform": "linux",
+			"state":    "available",
+			"uri":      "/machineimage" + name,
+		}
+	}
+}
+
+// Returns the three part name of the simulated user, e.g. /Compute-domain/user
+func (s *Server) computeUser() string {
+	return fmt.Sprintf("/Compute-%s/%s", s.IdentityDomain, s.User)
+}
+
+func (s *Server) handleCompute(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+
+	if path == "/authenticate/" {
+		s.computeAuthenticate(w, r)
+		return
+	}
+
+	cookie, err := r.Cookie(computeCookieName)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	if issued, ok := s.cookies[cookie.Value]; !ok || time.Since(issued) > computeCookieTTL {
+		writeError(w, http.StatusUnauthorized, "Authentication cookie is invalid or has expired")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		body, err := readJSON(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Unable to parse request body: %s", err)
+			return
+		}
+		if path == "/launchplan/" {
+			s.computeLaunchPlan(w, body)
+			return
+		}
+		if path == "/rebootinstancerequest/" {
+			s.computeRebootInstance(w, body)
+			return
+		}
+		s.computeCreate(w, path, body)
+	case http.MethodGet:
+		if strings.HasSuffix(path, "/") {
+			s.computeList(w, r, path)
+			return
+		}
+		object, ok := s.compute[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, "No such object: %s", path)
+			return
+		}
+		writeJSON(w, computeContentType, http.StatusOK, s.computeView(path, object))
+	case http.MethodPut:
+		body, err := readJSON(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Unable to parse request body: %s", err)
+			return
+		}
+		s.computeUpdate(w, path, body)
+	case http.MethodDelete:
+		s.computeDelete(w, path)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method %s is not supported", r.Method)
+	}
+}
+
+func (s *Server) computeAuthenticate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method %s is not supported", r.Method)
+		return
+	}
+	body, err := readJSON(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Unable to parse request body: %s", err)
+		return
+	}
+	if body["user"] != s.computeUser() || body["password"] != s.Password {
+		writeError(w, http.StatusUnauthorized, "Incorrect username or password")
+		return
+	}
+
+	value := newID()
+	s.cookies[value] = time.Now()
+	http.SetCookie(w, &http.Cookie{
+		Name:    computeCookieName,
+		Value:   value,
+		Path:    "/",
+		Expires: time.Now().Add(computeCookieTTL),
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Creates an object in the collection addressed by the container path. Objects are keyed by the
+// resource root and their fully qualified name, which is how the SDK addresses them afterwards.
+func (s *Server) computeCreate(w http.ResponseWriter, container string, body map[string]interface{}) {
+	root := strings.TrimSuffix(container, "/")
+
+	var key string
+	if strings.HasSuffix(container, "/entry/") {
+		// Image list entries are addressed by their version rather than a name
+		version, ok := body["version"]
+		if !ok {
+			writeError(w, http.StatusBadRequest, "version is required")
+			return
+		}
+		key = fmt.Sprintf("%s/%v", root, version)
+		if _, ok := s.compute[strings.TrimSuffix(root, "/entry")]; !ok {
+			writeError(w, http.StatusNotFound, "No such image list: %s", strings.TrimSuffix(root, "/entry"))
+			return
+		}
+	} else {
+		name, _ := body["name"].(string)
+		if name == "" {
+			name = fmt.Sprintf("%s/%s", s.computeUser(), newID())
+			body["name"] = name
+		}
+		key = root + name
+	}
+
+	if _, ok := s.compute[key]; ok {
+		writeError(w, http.StatusConflict, "Conflict: object %s already exists", key)
+		return
+	}
+
+	object := clone(body)
+	object["uri"] = s.URL + key
+	s.computeDefaults(root, object)
+	s.compute[key] = object
+
+	if root == "/platform/v1/orchestration" {
+		s.applyOrchestration(object)
+	}
+
+	writeJSON(w, computeContentType, http.StatusCreated, s.computeView(key, object))
+}
+
+// Launch plans create one instance per entry, returning each with its generated id
+func (s *Server) computeLaunchPlan(w http.ResponseWriter, body map[string]interface{}) {
+	templates, _ := body["instances"].([]interface{})
+	if len(templates) == 0 {
+		writeError(w, http.StatusBadRequest, "At least one instance is required")
+		return
+	}
+
+	instances := make([]interface{}, 0, len(templates))
+	for _, t := range templates {
+		template, ok := t.(map[string]interface{})
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Invalid instance: %v", t)
+			return
+		}
+		instance, err := s.launchInstance(template)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		view := clone(instance)
+		view["id"] = instance["id"]
+		instances = append(instances, view)
+	}
+
+	writeJSON(w, computeContentType, http.StatusCreated, map[string]interface{}{
+		"instances": instances,
+	})
+}
+
+// Creates a running instance, along with its storage attachments, from a launch plan or
+// orchestration template
+func (s *Server) launchInstance(template map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := template["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	shape, _ := template["shape"].(string)
+	if shape == "" {
+		return nil, fmt.Errorf("shape is required")
+	}
+	if _, ok := s.compute["/shape/"+shape]; !ok {
+		return nil, fmt.Errorf("Shape %s is not valid", shape)
+	}
+	if imageList, _ := template["imagelist"].(string); imageList != "" {
+		if _, ok := s.compute["/imagelist"+imageList]; !ok {
+			return nil, fmt.Errorf("No such image list: %s", imageList)
+		}
+	}
+
+	id := newID()
+	fqdn := fmt.Sprintf("%s/%s", name, id)
+	key := "/instance" + fqdn
+
+	instance := clone(template)
+	instance["id"] = id
+	instance["name"] = fqdn
+	instance["uri"] = s.URL + key
+
+	hostname, _ := template["hostname"].(string)
+	if hostname == "" {
+		hostname = strings.Replace(id, "-", "", -1)[:12]
+	}
+	instance["hostname"] = fmt.Sprintf("%s.compute-%s.oraclecloud.internal", hostname, s.IdentityDomain)
+
+	setDefault(instance, "attributes", map[string]interface{}{})
+	setDefault(instance, "desired_state", "running")
+	setDefault(instance, "label", hostname)
+	setDefault(instance, "sshkeys", []interface{}{})
+	setDefault(instance, "tags", []interface{}{})
+	setDefault(instance, "boot_order", []interface{}{})
+	instance["state"] = instance["desired_state"]
+	if s.failedLaunches > 0 {
+		s.failedLaunches--
+		instance["state"] = "error"
+		instance["error_reason"] = "No capacity is available for the instance's shape"
+	}
+	instance["account"] = fmt.Sprintf("/Compute-%s/default", s.IdentityDomain)
+	instance["availability_domain"] = "/uscom-central-1a"
+	instance["domain"] = fmt.Sprintf("compute-%s.oraclecloud.internal", s.IdentityDomain)
+	instance["ip"] = randomIP("10")
+	instance["placement_requirements"] = []interface{}{"/system/compute/allow_instances", "/system/compute/placement/default"}
+	instance["platform"] = "linux"
+	instance["priority"] = "/oracle/public/default"
+	instance["quota_reservation"] = nil
+	instance["relationships"] = []interface{}{}
+	instance["resolvers"] = []interface{}{}
+	instance["site"] = ""
+	instance["start_time"] = timestamp()
+	instance["vcable_id"] = fmt.Sprintf("%s/%s", s.computeUser(), newID())
+	instance["vnc"] = randomIP("10") + ":5900"
+	if _, ok := template["virtio"]; !ok {
+		instance["virtio"] = false
+	}
+
+	networking, _ := template["networking"].(map[string]interface{})
+	if networking == nil {
+		networking = map[string]interface{}{
+			"eth0": map[string]interface{}{},
+		}
+	}
+	for iface, n := range networking {
+		info, _ := n.(map[string]interface{})
+		if info == nil {
+			info = map[string]interface{}{}
+		}
+		if network, _ := info["ipnetwork"].(string); network != "" {
+			if err := s.validateIPNetworkAddress(network, info["ip"]); err != nil {
+				return nil, err
+			}
+			setDefault(info, "vnic", fmt.Sprintf("%s_%s", name, iface))
+			setDefault(info, "address", randomMAC())
+			vnic := info["vnic"].(string)
+			s.compute["/network/v1/vnic"+vnic] = map[string]interface{}{
+				"name":        vnic,
+				"description": "",
+				"macAddress":  info["address"],
+				"tags":        []interface{}{},
+				"transitFlag": false,
+				"uri":         s.URL + "/network/v1/vnic" + vnic,
+			}
+			if sets, ok := info["vnicsets"].([]interface{}); ok {
+				for _, set := range sets {
+					if vnicSet, ok := s.compute["/network/v1/vnicset"+fmt.Sprint(set)]; ok {
+						vnics, _ := vnicSet["vnics"].([]interface{})
+						vnicSet["vnics"] = append(vnics, vnic)
+					}
+				}
+			}
+		} else {
+			// Shared network interfaces are placed in the default security list
+			if seclists, _ := info["seclists"].([]interface{}); len(seclists) == 0 {
+				info["seclists"] = []interface{}{fmt.Sprintf("/Compute-%s/default/default", s.IdentityDomain)}
+			}
+			info["vethernet"] = "/oracle/public/default"
+		}
+		setDefault(info, "model", "")
+		networking[iface] = info
+	}
+	instance["networking"] = networking
+
+	attachments := []interface{}{}
+	if storage, ok := template["storage_attachments"].([]interface{}); ok {
+		for _, a := range storage {
+			attachment, _ := a.(map[string]interface{})
+			volume, _ := attachment["volume"].(string)
+			if _, ok := s.compute["/storage/volume"+volume]; !ok {
+				return nil, fmt.Errorf("No such storage volume: %s", volume)
+			}
+			attachmentName := fmt.Sprintf("%s/%s", fqdn, newID())
+			attachments = append(attachments, map[string]interface{}{
+				"index":               attachment["index"],
+				"name":                attachmentName,
+				"storage_volume_name": volume,
+			})
+			s.compute["/storage/attachment"+attachmentName] = map[string]interface{}{
+				"account":             nil,
+				"index":               attachment["index"],
+				"instance_name":       fqdn,
+				"name":                attachmentName,
+				"storage_volume_name": volume,
+				"state":               "attached",
+				"uri":                 s.URL + "/storage/attachment" + attachmentName,
+			}
+		}
+	}
+	instance["storage_attachments"] = attachments
+
+	s.compute[key] = instance
+	s.compute["/instanceconsole"+fqdn] = map[string]interface{}{
+		"name":      fqdn,
+		"output":    fmt.Sprintf("Booting instance %s\n%s login:\n", fqdn, hostname),
+		"timestamp": timestamp(),
+	}
+	return instance, nil
+}
+
+// Reboot requests complete immediately, restarting the instance and adding to its console output
+func (s *Server) computeRebootInstance(w http.ResponseWriter, body map[string]interface{}) {
+	instanceName, _ := body["instance"].(string)
+	instance, ok := s.compute["/instance"+instanceName]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such instance: %s", instanceName)
+		return
+	}
+	if instance["state"] != "running" {
+		writeError(w, http.StatusConflict, "Instance %s is not running", instanceName)
+		return
+	}
+
+	name := fmt.Sprintf("%s/%s", s.computeUser(), newID())
+	key := "/rebootinstancerequest" + name
+	hard, _ := body["hard"].(bool)
+	request := map[string]interface{}{
+		"name":          name,
+		"instance":      instanceName,
+		"instance_id":   instance["id"],
+		"hard":          hard,
+		"request_state": "complete",
+		"error_reason":  "",
+		"creation_time": timestamp(),
+		"uri":           s.URL + key,
+	}
+	s.compute[key] = request
+
+	instance["start_time"] = timestamp()
+	if console, ok := s.compute["/instanceconsole"+instanceName]; ok {
+		console["output"] = fmt.Sprintf("%sRebooting instance %s\nBooting instance %s\n", console["output"], instanceName, instanceName)
+		console["timestamp"] = timestamp()
+	}
+
+	writeJSON(w, computeContentType, http.StatusCreated, request)
+}
+
+// Checks that a static address for an IP network interface is within the network's prefix
+func (s *Server) validateIPNetworkAddress(network string, address interface{}) error {
+	ipNetwork, ok := s.compute["/network/v1/ipnetwork"+network]
+	if !ok {
+		return fmt.Errorf("No such IP network: %s", network)
+	}
+	ip, _ := address.(string)
+	if ip == "" {
+		return nil
+	}
+	_, prefix, err := net.ParseCIDR(fmt.Sprint(ipNetwork["ipAddressPrefix"]))
+	if err != nil {
+		return fmt.Errorf("Invalid prefix for IP network %s: %s", network, err)
+	}
+	if !prefix.Contains(net.ParseIP(ip)) {
+		return fmt.Errorf("IP address %s is not within the prefix %s of IP network %s", ip, prefix, network)
+	}
+	return nil
+}
+
+func (s *Server) computeUpdate(w http.ResponseWriter, path string, body map[string]interface{}) {
+	object, ok := s.compute[path]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such object: %s", path)
+		return
+	}
+
+	if strings.HasPrefix(path, "/instance/") {
+		if shape, ok := body["shape"]; ok && shape != nil && shape != object["shape"] {
+			if boot, _ := object["boot_order"].([]interface{}); len(boot) == 0 {
+				writeError(w, http.StatusBadRequest, "The shape of instance %s can't be changed, as it doesn't boot from a storage volume", object["name"])
+				return
+			}
+			if object["state"] != "shutdown" {
+				writeError(w, http.StatusConflict, "Instance %s must be shut down to change its shape", object["name"])
+				return
+			}
+		}
+	}
+
+	for k, v := range body {
+		// The name and id of an object can't be changed, and instance names include their id.
+		// Attributes which are null are left unchanged.
+		if k == "name" || k == "id" || v == nil {
+			continue
+		}
+		object[k] = v
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/seclist/"):
+		object["policy"] = strings.ToUpper(fmt.Sprint(object["policy"]))
+		object["outbound_cidr_policy"] = strings.ToUpper(fmt.Sprint(object["outbound_cidr_policy"]))
+	case strings.HasPrefix(path, "/instance/"):
+		object["state"] = object["desired_state"]
+	case strings.HasPrefix(path, "/platform/v1/orchestration/"):
+		object["version"] = toInt(object["version"]) + 1
+		object["time_updated"] = timestamp()
+		s.applyOrchestration(object)
+	}
+
+	writeJSON(w, computeContentType, http.StatusOK, s.computeView(path, object))
+}
+
+func (s *Server) computeDelete(w http.ResponseWriter, path string) {
+	object, ok := s.compute[path]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such object: %s", path)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/instance/"):
+		s.deleteInstance(path, object)
+	case strings.HasPrefix(path, "/platform/v1/orchestration/"):
+		// Terminating an orchestration removes every object it manages
+		object["desired_state"] = "inactive"
+		s.applyOrchestration(object)
+	case strings.HasPrefix(path, "/storage/volume/"):
+		for _, attachment := range s.computeObjects("/storage/attachment/") {
+			if attachment["storage_volume_name"] == object["name"] {
+				writeError(w, http.StatusConflict, "Storage volume %s is in use", object["name"])
+				return
+			}
+		}
+	case strings.HasPrefix(path, "/imagelist/"):
+		for key := range s.compute {
+			if strings.HasPrefix(key, path+"/entry/") {
+				delete(s.compute, key)
+			}
+		}
+	}
+
+	delete(s.compute, path)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteInstance(path string, instance map[string]interface{}) {
+	delete(s.compute, "/instanceconsole"+instance["name"].(string))
+	for _, attachment := range s.computeObjects("/storage/attachment/") {
+		if attachment["instance_name"] == instance["name"] {
+			delete(s.compute, "/storage/attachment"+attachment["name"].(string))
+		}
+	}
+	if networking, ok := instance["networking"].(map[string]interface{}); ok {
+		for _, n := range networking {
+			if vnic, _ := n.(map[string]interface{})["vnic"].(string); vnic != "" {
+				delete(s.compute, "/network/v1/vnic"+vnic)
+				for _, vnicSet := range s.computeObjects("/network/v1/vnicset/") {
+					vnics, _ := vnicSet["vnics"].([]interface{})
+					remaining := []interface{}{}
+					for _, v := range vnics {
+						if v != vnic {
+							remaining = append(remaining, v)
+						}
+					}
+					vnicSet["vnics"] = remaining
+				}
+			}
+		}
+	}
+	delete(s.compute, path)
+}
+
+func (s *Server) computeList(w http.ResponseWriter, r *http.Request, path string) {
+	objects := s.computeObjects(path)
+	// Objects must have every tag given in the tags query filter
+	required := r.URL.Query()["tags"]
+
+	result := make([]interface{}, 0, len(objects))
+	for _, key := range sortedKeys(objects) {
+		if !hasTags(objects[key], required) {
+			continue
+		}
+		result = append(result, s.computeView(key, objects[key]))
+	}
+
+	writeJSON(w, computeContentType, http.StatusOK, map[string]interface{}{
+		"result": result,
+	})
+}
+
+func hasTags(object map[string]interface{}, required []string) bool {
+	tags, _ := object["tags"].([]interface{})
+	for _, r := range required {
+		found := false
+		for _, t := range tags {
+			if t == r {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the stored objects whose path starts with the given prefix
+func (s *Server) computeObjects(prefix string) map[string]map[string]interface{} {
+	objects := make(map[string]map[string]interface{})
+	for key, object := range s.compute {
+		if strings.HasPrefix(key, prefix) && !strings.Contains(strings.TrimPrefix(key, prefix), "/entry/") {
+			objects[key] = object
+		}
+	}
+	return objects
+}
+
+// Returns a copy of the object as the API would return it
+func (s *Server) computeView(key string, object map[string]interface{}) map[string]interface{} {
+	view := clone(object)
+	if strings.HasPrefix(key, "/instance/") {
+		// The id is only known to the client through the instance name
+		delete(view, "id")
+		// Volumes attached after launch are listed along with those attached at launch
+		attachments := []interface{}{}
+		for _, attachmentKey := range sortedKeys(s.computeObjects("/storage/attachment/")) {
+			attachment := s.compute[attachmentKey]
+			if attachment["instance_name"] == object["name"] {
+				attachments = append(attachments, map[string]interface{}{
+					"index":               attachment["index"],
+					"name":                attachment["name"],
+					"storage_volume_name": attachment["storage_volume_name"],
+				})
+			}
+		}
+		sort.Slice(attachments, func(i, j int) bool {
+			return toInt(attachments[i].(map[string]interface{})["index"]) < toInt(attachments[j].(map[string]interface{})["index"])
+		})
+		view["storage_attachments"] = attachments
+	}
+	if strings.HasPrefix(key, "/imagelist/") && !strings.Contains(key, "/entry/") {
+		entries := []interface{}{}
+		for _, entryKey := range sortedKeys(s.computeObjects(key + "/entry/")) {
+			entries = append(entries, clone(s.compute[entryKey]))
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return toInt(entries[i].(map[string]interface{})["version"]) < toInt(entries[j].(map[string]interface{})["version"])
+		})
+		view["entries"] = entries
+	}
+	return view
+}
+
+// Populates the attributes the API computes for newly created objects
+func (s *Server) computeDefaults(root string, object map[string]interface{}) {
+	setDefault(object, "account", fmt.Sprintf("/Compute-%s/default", s.IdentityDomain))
+
+	switch root {
+	case "/storage/volume":
+		object["status"] = "Online"
+		object["status_timestamp"] = timestamp()
+		setDefault(object, "properties", []interface{}{"/oracle/public/storage/default"})
+		setDefault(object, "bootable", object["imagelist"] != nil)
+		setDefault(object, "managed", true)
+		setDefault(object, "platform", "linux")
+		setDefault(object, "writecache", false)
+		setDefault(object, "machineimage_name", "")
+		setDefault(object, "readonly", false)
+		setDefault(object, "hypervisor", nil)
+		object["storage_pool"] = "/uscom-central-1a/chi1-opc-c10r310-zfs-1-v1/storagepool/iscsi/latency_1"
+		// Volumes restored from a snapshot report both its name and id
+		for _, snapshot := range s.computeObjects("/storage/snapshot/") {
+			if snapshot["snapshot_id"] == object["snapshot_id"] || snapshot["name"] == object["snapshot"] {
+				object["snapshot"] = snapshot["name"]
+				object["snapshot_id"] = snapshot["snapshot_id"]
+				object["snapshot_account"] = snapshot["account"]
+			}
+		}
+	case "/storage/attachment":
+		object["state"] = "attached"
+	case "/storage/snapshot":
+		object["status"] = "completed"
+		object["status_timestamp"] = timestamp()
+		setDefault(object, "property", "/oracle/public/storage/snapshot/default")
+		setDefault(object, "snapshot_id", strings.Replace(newID(), "-", "", -1))
+		setDefault(object, "snapshot_timestamp", timestamp())
+		setDefault(object, "parent_volume_bootable", "false")
+		setDefault(object, "start_timestamp", timestamp())
+		setDefault(object, "platform", "linux")
+		if volume, ok := s.compute["/storage/volume"+fmt.Sprint(object["volume"])]; ok {
+			object["size"] = volume["size"]
+		}
+	case "/snapshot":
+		object["state"] = "complete"
+		setDefault(object, "machineimage", fmt.Sprintf("%s/%s", s.computeUser(), newID()))
+		if image, _ := object["machineimage"].(string); image != "" {
+			s.compute["/machineimage"+image] = map[string]interface{}{
+				"name":     image,
+				"account":  object["account"],
+				"platform": "linux",
+				"state":    "available",
+				"uri":      s.URL + "/machineimage" + image,
+			}
+		}
+	case "/machineimage":
+		object["state"] = "available"
+		setDefault(object, "platform", "linux")
+	case "/imagelist":
+		setDefault(object, "default", 1)
+	case "/seclist":
+		// Security list policies are always returned in upper case
+		setDefault(object, "policy", "deny")
+		setDefault(object, "outbound_cidr_policy", "permit")
+		object["policy"] = strings.ToUpper(fmt.Sprint(object["policy"]))
+		object["outbound_cidr_policy"] = strings.ToUpper(fmt.Sprint(object["outbound_cidr_policy"]))
+	case "/ip/reservation":
+		object["ip"] = randomIP("129.150")
+		object["used"] = false
+	case "/network/v1/ipreservation":
+		object["ipAddress"] = randomIP("129.150")
+	case "/network/v1/vnicset":
+		setDefault(object, "vnics", []interface{}{})
+	case "/vpnendpoint/v2":
+		object["lifecycleState"] = "ready"
+		object["tunnelStatus"] = "UP"
+	case "/platform/v1/orchestration":
+		object["id"] = newID()
+		object["version"] = 1
+		object["time_created"] = timestamp()
+		object["time_updated"] = timestamp()
+		object["user"] = s.computeUser()
+	}
+}
+
+// Drives an orchestration, and the instances it manages, to its desired state
+func (s *Server) applyOrchestration(orchestration map[string]interface{}) {
+	desired, _ := orchestration["desired_state"].(string)
+	if desired == "" {
+		desired = "active"
+		orchestration["desired_state"] = desired
+	}
+
+	objects, _ := orchestration["objects"].([]interface{})
+	for _, o := range objects {
+		object, _ := o.(map[string]interface{})
+		if object == nil || object["type"] != "Instance" {
+			continue
+		}
+		template, _ := object["template"].(map[string]interface{})
+		if template == nil {
+			continue
+		}
+		name, _ := template["name"].(string)
+		persistent, _ := object["persistent"].(bool)
+
+		existing := s.findInstances(name)
+		keep := desired == "active" || (desired == "suspend" && persistent)
+		if !keep {
+			for key, instance := range existing {
+				s.deleteInstance(key, instance)
+			}
+			object["health"] = map[string]interface{}{"status": "inactive"}
+			continue
+		}
+
+		if len(existing) == 0 {
+			if _, err := s.launchInstance(template); err != nil {
+				orchestration["status"] = "terminal_error"
+				object["health"] = map[string]interface{}{
+					"status": "terminal_error",
+					"error":  err.Error(),
+				}
+				return
+			}
+		}
+		object["health"] = map[string]interface{}{"status": "active"}
+		setDefault(object, "orchestration", orchestration["name"])
+		setDefault(object, "name", fmt.Sprintf("%s/instance/%s", orchestration["name"], object["label"]))
+	}
+
+	orchestration["status"] = desired
+}
+
+// Returns the instances with the given fully qualified name, keyed by their path
+func (s *Server) findInstances(name string) map[string]map[string]interface{} {
+	return s.computeObjects("/instance" + name + "/")
+}
+
+func randomIP(prefix string) string {
+	id := newID()
+	octets := strings.Count(prefix, ".") + 1
+	parts := []string{prefix}
+	for i := octets; i < 4; i++ {
+		b, _ := strconv.ParseUint(id[i*2:i*2+2], 16, 8)
+		if b == 0 || b == 255 {
+			b = 1
+		}
+		parts = append(parts, strconv.Itoa(int(b)))
+	}
+	return strings.Join(parts, ".")
+}
+
+func randomMAC() string {
+	id := strings.Replace(newID(), "-", "", -1)
+	return fmt.Sprintf("c6:b0:%s:%s:%s:%s", id[0:2], id[2:4], id[4:6], id[6:8])
+}
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,141 @@
+package simulator
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const lbaasContentType = "application/vnd.com.oracle.oracloud.lbaas.VLBR+json"
+
+// Child collections of a load balancer, e.g. /vlbrs/{region}/{name}/listeners
+var lbaasChildren = []string{"listeners", "originserverpools", "policies"}
+
+func (s *Server) handleLBaaS(w http.ResponseWriter, r *http.Request) {
+	user, password, ok := r.BasicAuth()
+	if !ok || user != s.User || password != s.Password {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	method := r.Method
+	if override := r.Header.Get("X-HTTP-Method-Override"); override != "" {
+		method = override
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = lbaasContentType
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	switch method {
+	case http.MethodPost:
+		body, err := readJSON(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Unable to parse request body: %s", err)
+			return
+		}
+		s.lbaasCreate(w, contentType, path, parts, body)
+	case http.MethodGet:
+		object, ok := s.lbaas[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, "No such service exits: %s", path)
+			return
+		}
+		writeJSON(w, contentType, http.StatusOK, clone(object))
+	case http.MethodPut, "PATCH":
+		object, ok := s.lbaas[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, "No such service exits: %s", path)
+			return
+		}
+		body, err := readJSON(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Unable to parse request body: %s", err)
+			return
+		}
+		for k, v := range body {
+			if k == "name" || k == "region" {
+				continue
+			}
+			object[k] = v
+		}
+		object["state"] = lbaasHealthyState(path)
+		writeJSON(w, contentType, http.StatusOK, clone(object))
+	case http.MethodDelete:
+		object, ok := s.lbaas[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, "No such service exits: %s", path)
+			return
+		}
+		// Deleting a load balancer also deletes all of its child resources
+		for key := range s.lbaas {
+			if strings.HasPrefix(key, path+"/") {
+				delete(s.lbaas, key)
+			}
+		}
+		delete(s.lbaas, path)
+		result := clone(object)
+		result["state"] = "DELETED"
+		writeJSON(w, contentType, http.StatusOK, result)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method %s is not supported", method)
+	}
+}
+
+func (s *Server) lbaasCreate(w http.ResponseWriter, contentType, path string, parts []string, body map[string]interface{}) {
+	name, _ := body["name"].(string)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	var key string
+	switch {
+	case path == "/vlbrs":
+		region, _ := body["region"].(string)
+		if region == "" {
+			writeError(w, http.StatusBadRequest, "region is required")
+			return
+		}
+		key = fmt.Sprintf("/vlbrs/%s/%s", region, name)
+		setDefault(body, "canonical_host_name", fmt.Sprintf("%s-%s.%s.lbaas.oraclecloud.com", name, s.IdentityDomain, region))
+		setDefault(body, "ip_network_name", "")
+		setDefault(body, "scheme", "INTERNET_FACING")
+	case path == "/certs":
+		key = "/certs/" + name
+	case len(parts) == 4 && parts[0] == "vlbrs" && contains(lbaasChildren, parts[3]):
+		if _, ok := s.lbaas[fmt.Sprintf("/vlbrs/%s/%s", parts[1], parts[2])]; !ok {
+			writeError(w, http.StatusNotFound, "No such service exits: /vlbrs/%s/%s", parts[1], parts[2])
+			return
+		}
+		key = path + "/" + name
+		setDefault(body, "parent_lbr", fmt.Sprintf("%s/vlbrs/%s/%s", s.URL, parts[1], parts[2]))
+	default:
+		writeError(w, http.StatusNotFound, "No such service exits: %s", path)
+		return
+	}
+
+	if _, ok := s.lbaas[key]; ok {
+		writeError(w, http.StatusConflict, "%s already exists", key)
+		return
+	}
+
+	object := clone(body)
+	object["uri"] = s.URL + key
+	object["state"] = lbaasHealthyState(key)
+	s.lbaas[key] = object
+
+	writeJSON(w, contentType, http.StatusCreated, clone(object))
+}
+
+// Certificates report CREATED once ready, all other resources report HEALTHY
+func lbaasHealthyState(key string) string {
+	if strings.HasPrefix(key, "/certs/") {
+		return "CREATED"
+	}
+	return "HEALTHY"
+}
@@ -0,0 +1,189 @@
+// Package simulator provides an in-memory fake of the Oracle Cloud Infrastructure
+// Classic REST APIs used by the provider. A single Server answers the Compute Classic,
+// Object Storage Classic and Load Balancer Classic endpoints so that the acceptance
+// tests can run without access to a real account.
+package simulator
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultIdentityDomain is the identity domain used by New
+	DefaultIdentityDomain = "simulator"
+	// DefaultUser is the user name used by New
+	DefaultUser = "simulator-user"
+	// DefaultPassword is the password used by New
+	DefaultPassword = "simulator-password"
+)
+
+// Server is a stateful, in-process fake of the OPC APIs.
+type Server struct {
+	*httptest.Server
+
+	IdentityDomain string
+	User           string
+	Password       string
+
+	mu sync.Mutex
+
+	// Compute Classic objects keyed by their request path, e.g. /seclist/Compute-domain/user/name
+	compute map[string]map[string]interface{}
+	// Authentication cookies and the time they were issued
+	cookies map[string]time.Time
+
+	// Object Storage containers keyed by their account qualified name, e.g. Storage-domain/name
+	containers map[string]*container
+	// Authentication tokens and the account they were issued for
+	tokens map[string]*storageSession
+
+	// Load Balancer Classic objects keyed by their request path, e.g. /vlbrs/region/name
+	lbaas map[string]map[string]interface{}
+
+	// The number of instances still to be launched in the error state
+	failedLaunches int
+}
+
+// New starts a simulator with the default credentials
+func New() *Server {
+	return NewServer(DefaultIdentityDomain, DefaultUser, DefaultPassword)
+}
+
+// NewServer starts a simulator that accepts the supplied credentials
+func NewServer(identityDomain, user, password string) *Server {
+	s := &Server{
+		IdentityDomain: identityDomain,
+		User:           user,
+		Password:       password,
+		compute:        make(map[string]map[string]interface{}),
+		cookies:        make(map[string]time.Time),
+		containers:     make(map[string]*container),
+		tokens:         make(map[string]*storageSession),
+		lbaas:          make(map[string]map[string]interface{}),
+	}
+	s.seedCompute()
+	s.seedStorage()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// ComputeEndpoint returns the endpoint to use for the Compute Classic API
+func (s *Server) ComputeEndpoint() string {
+	return s.URL
+}
+
+// StorageEndpoint returns the endpoint to use for the Object Storage Classic API
+func (s *Server) StorageEndpoint() string {
+	return s.URL
+}
+
+// LBaaSEndpoint returns the endpoint to use for the Load Balancer Classic API
+func (s *Server) LBaaSEndpoint() string {
+	return s.URL
+}
+
+// ExpireSessions invalidates every compute cookie and storage token that has been issued,
+// as happens when a session times out
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cookies = make(map[string]time.Time)
+	s.tokens = make(map[string]*storageSession)
+}
+
+// FailInstanceLaunches makes the next instances launched end in the error state, rather than
+// running, as happens when the site has no capacity for them
+func (s *Server) FailInstanceLaunches(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failedLaunches = count
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if os.Getenv("ORACLE_LOG") != "" {
+		log.Printf("[DEBUG] Simulator received request: %s, %s\n", r.Method, r.URL)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The storage API is recognised by its authentication headers as well as its path, as
+	// some requests are made relative to the account rather than the endpoint root
+	switch path := r.URL.Path; {
+	case path == "/auth/v1.0" || strings.HasPrefix(path, "/v1/") || r.Header.Get(storageAuthHeader) != "":
+		s.handleStorage(w, r)
+	case path == "/vlbrs" || strings.HasPrefix(path, "/vlbrs/") ||
+		path == "/certs" || strings.HasPrefix(path, "/certs/"):
+		s.handleLBaaS(w, r)
+	default:
+		s.handleCompute(w, r)
+	}
+}
+
+// Returns a random identifier in the same format as the ids generated by the API
+func newID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16])
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, status int, body interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if body != nil {
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, "application/json", status, map[string]interface{}{
+		"message": fmt.Sprintf(format, args...),
+	})
+}
+
+func readJSON(r *http.Request) (map[string]interface{}, error) {
+	body := make(map[string]interface{})
+	if r.Body == nil {
+		return body, nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err.Error() != "EOF" {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Returns a deep copy of a JSON object, so stored state can't be modified by callers
+func clone(object map[string]interface{}) map[string]interface{} {
+	b, err := json.Marshal(object)
+	if err != nil {
+		panic(err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func setDefault(object map[string]interface{}, key string, value interface{}) {
+	if v, ok := object[key]; !ok || v == nil || v == "" {
+		object[key] = value
+	}
+}
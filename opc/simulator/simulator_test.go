@@ -0,0 +1,227 @@
+package simulator
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/client"
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/go-oracle-terraform/lbaas"
+	"github.com/hashicorp/go-oracle-terraform/opc"
+	"github.com/hashicorp/go-oracle-terraform/storage"
+)
+
+func testConfig(t *testing.T, s *Server) *opc.Config {
+	endpoint, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &opc.Config{
+		Username:       opc.String(s.User),
+		Password:       opc.String(s.Password),
+		IdentityDomain: opc.String(s.IdentityDomain),
+		APIEndpoint:    endpoint,
+		MaxRetries:     opc.Int(1),
+		HTTPClient:     http.DefaultClient,
+	}
+}
+
+func TestSimulator_computeAuthentication(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	config := testConfig(t, s)
+	config.Password = opc.String("incorrect")
+	if _, err := compute.NewComputeClient(config); err == nil {
+		t.Fatal("Expected authentication with an incorrect password to fail")
+	}
+
+	if _, err := compute.NewComputeClient(testConfig(t, s)); err != nil {
+		t.Fatalf("Error authenticating: %s", err)
+	}
+}
+
+func TestSimulator_computeResource(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	computeClient, err := compute.NewComputeClient(testConfig(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secListClient := computeClient.SecurityLists()
+
+	created, err := secListClient.CreateSecurityList(&compute.CreateSecurityListInput{
+		Name:               "test-seclist",
+		Policy:             compute.SecurityListPolicyDeny,
+		OutboundCIDRPolicy: compute.SecurityListPolicyPermit,
+	})
+	if err != nil {
+		t.Fatalf("Error creating security list: %s", err)
+	}
+	if created.Name != "test-seclist" {
+		t.Fatalf("Expected name %q, got %q", "test-seclist", created.Name)
+	}
+
+	if _, err := secListClient.CreateSecurityList(&compute.CreateSecurityListInput{Name: "test-seclist"}); err == nil {
+		t.Fatal("Expected creating a duplicate security list to fail")
+	}
+
+	updated, err := secListClient.UpdateSecurityList(&compute.UpdateSecurityListInput{
+		Name:               "test-seclist",
+		Policy:             compute.SecurityListPolicyPermit,
+		OutboundCIDRPolicy: compute.SecurityListPolicyPermit,
+	})
+	if err != nil {
+		t.Fatalf("Error updating security list: %s", err)
+	}
+	// The API reports policies in upper case
+	if !strings.EqualFold(string(updated.Policy), string(compute.SecurityListPolicyPermit)) {
+		t.Fatalf("Expected policy %q, got %q", compute.SecurityListPolicyPermit, updated.Policy)
+	}
+
+	if err := secListClient.DeleteSecurityList(&compute.DeleteSecurityListInput{Name: "test-seclist"}); err != nil {
+		t.Fatalf("Error deleting security list: %s", err)
+	}
+	_, err = secListClient.GetSecurityList(&compute.GetSecurityListInput{Name: "test-seclist"})
+	if !client.WasNotFoundError(err) {
+		t.Fatalf("Expected a not found error after deletion, got %v", err)
+	}
+}
+
+func TestSimulator_storageVolume(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	computeClient, err := compute.NewComputeClient(testConfig(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	volumeClient := computeClient.StorageVolumes()
+
+	volume, err := volumeClient.CreateStorageVolume(&compute.CreateStorageVolumeInput{
+		Name:         "test-volume",
+		Size:         "10",
+		Properties:   []string{"/oracle/public/storage/default"},
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Error creating storage volume: %s", err)
+	}
+	if volume.Status != "Online" {
+		t.Fatalf("Expected status %q, got %q", "Online", volume.Status)
+	}
+
+	if err := volumeClient.DeleteStorageVolume(&compute.DeleteStorageVolumeInput{
+		Name:         "test-volume",
+		PollInterval: 10 * time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Error deleting storage volume: %s", err)
+	}
+}
+
+func TestSimulator_storage(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	storageClient, err := storage.NewStorageClient(testConfig(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = storageClient.CreateContainer(&storage.CreateContainerInput{
+		Name:           "test-container",
+		CustomMetadata: map[string]string{"Foo": "bar"},
+	})
+	if err != nil {
+		t.Fatalf("Error creating container: %s", err)
+	}
+	container, err := storageClient.GetContainer(&storage.GetContainerInput{Name: "test-container"})
+	if err != nil {
+		t.Fatalf("Error reading container: %s", err)
+	}
+	if container.CustomMetadata["Foo"] != "bar" {
+		t.Fatalf("Expected metadata Foo=bar, got %v", container.CustomMetadata)
+	}
+
+	objectClient := storageClient.Objects()
+	object, err := objectClient.CreateObject(&storage.CreateObjectInput{
+		Name:           "test-object",
+		Container:      "test-container",
+		Body:           bytes.NewReader([]byte("hello world")),
+		ObjectMetadata: map[string]string{"Foo": "bar"},
+	})
+	if err != nil {
+		t.Fatalf("Error creating object: %s", err)
+	}
+	if object.ContentLength != 11 {
+		t.Fatalf("Expected content length 11, got %d", object.ContentLength)
+	}
+	if object.ContentType != defaultContentType {
+		t.Fatalf("Expected content type %q, got %q", defaultContentType, object.ContentType)
+	}
+	if object.Etag != "5eb63bbbe01eeed093cb22bb8f5acdc3" {
+		t.Fatalf("Expected the MD5 of the body as the etag, got %q", object.Etag)
+	}
+	if object.ObjectMetadata["Foo"] != "bar" {
+		t.Fatalf("Expected metadata Foo=bar, got %v", object.ObjectMetadata)
+	}
+
+	ranged, err := objectClient.GetObject(&storage.GetObjectInput{
+		ID:    "test-container/test-object",
+		Range: "bytes=0-4",
+	})
+	if err != nil {
+		t.Fatalf("Error reading object range: %s", err)
+	}
+	if ranged.ContentLength != 5 {
+		t.Fatalf("Expected content length 5, got %d", ranged.ContentLength)
+	}
+
+	if err := storageClient.DeleteContainer(&storage.DeleteContainerInput{Name: "test-container"}); err == nil {
+		t.Fatal("Expected deleting a container that isn't empty to fail")
+	}
+	if err := objectClient.DeleteObject(&storage.DeleteObjectInput{ID: "test-container/test-object"}); err != nil {
+		t.Fatalf("Error deleting object: %s", err)
+	}
+	if err := storageClient.DeleteContainer(&storage.DeleteContainerInput{Name: "test-container"}); err != nil {
+		t.Fatalf("Error deleting container: %s", err)
+	}
+}
+
+func TestSimulator_lbaas(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	lbaasClient, err := lbaas.NewClient(testConfig(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lbClient := lbaasClient.LoadBalancerClient()
+
+	lb, err := lbClient.CreateLoadBalancer(&lbaas.CreateLoadBalancerInput{
+		Name:     "test-lb",
+		Region:   "uscom-central-1",
+		Scheme:   lbaas.LoadBalancerSchemeInternetFacing,
+		Disabled: lbaas.LBaaSDisabledFalse,
+	})
+	if err != nil {
+		t.Fatalf("Error creating load balancer: %s", err)
+	}
+	if lb.State != lbaas.LBaaSStateHealthy {
+		t.Fatalf("Expected state %q, got %q", lbaas.LBaaSStateHealthy, lb.State)
+	}
+
+	ctx := lbaas.LoadBalancerContext{Region: "uscom-central-1", Name: "test-lb"}
+	if _, err := lbClient.DeleteLoadBalancer(ctx); err != nil {
+		t.Fatalf("Error deleting load balancer: %s", err)
+	}
+	_, err = lbClient.GetLoadBalancer(ctx)
+	if !client.WasNotFoundError(err) {
+		t.Fatalf("Expected a not found error after deletion, got %v", err)
+	}
+}
@@ -0,0 +1,898 @@
+package simulator
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	storageAuthHeader  = "X-Auth-Token"
+	storageTokenTTL    = 30 * time.Minute
+	defaultContentType = "text/plain;charset=UTF-8"
+
+	hContainerMetaPrefix       = "X-Container-Meta-"
+	hRemoveContainerMetaPrefix = "X-Remove-Container-Meta-"
+	hObjectMetaPrefix          = "X-Object-Meta-"
+	hRemoveObjectMetaPrefix    = "X-Remove-Object-Meta-"
+
+	storageClassArchive = "Archive"
+	// How long a restore of an archived object takes, and how long the object can then be read for
+	archiveRestoreTime      = time.Second
+	archiveRestoreRetention = 24 * time.Hour
+)
+
+// Container level headers, other than custom metadata, which are persisted when supplied
+var containerHeaders = []string{
+	"X-Container-Read",
+	"X-Container-Write",
+	"X-Versions-Location",
+	"X-History-Location",
+}
+
+// Object level headers, other than custom metadata, which are persisted when supplied
+var objectHeaders = []string{
+	"Content-Disposition",
+	"Content-Encoding",
+	"X-Delete-At",
+	"X-Object-Manifest",
+}
+
+type storageSession struct {
+	account string
+	issued  time.Time
+}
+
+type container struct {
+	name    string
+	headers http.Header
+	objects map[string]*object
+	created time.Time
+}
+
+type object struct {
+	data     []byte
+	headers  http.Header
+	modified time.Time
+	// When the object was last restored, for objects in archive containers
+	restored time.Time
+}
+
+func (s *Server) seedStorage() {
+	account := "Storage-" + s.IdentityDomain
+	s.containers[account+"/compute_images"] = newContainer("compute_images")
+}
+
+func newContainer(name string) *container {
+	return &container{
+		name:    name,
+		headers: make(http.Header),
+		objects: make(map[string]*object),
+		created: time.Now(),
+	}
+}
+
+func (s *Server) handleStorage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/auth/v1.0" {
+		s.storageAuthenticate(w, r)
+		return
+	}
+
+	if r.URL.Query().Get("temp_url_sig") != "" {
+		s.storageTempURL(w, r)
+		return
+	}
+
+	session, ok := s.tokens[r.Header.Get(storageAuthHeader)]
+	if !ok || time.Since(session.issued) > storageTokenTTL {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Requests are normally addressed as /v1/{account}/{container}/{object}, but are also
+	// accepted relative to the authenticated account, e.g. /{container}/{object}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	account := session.account
+	if strings.HasPrefix(path, "v1/") {
+		parts := strings.SplitN(strings.TrimPrefix(path, "v1/"), "/", 2)
+		account = parts[0]
+		path = ""
+		if len(parts) > 1 {
+			path = parts[1]
+		}
+	}
+	if account != session.account {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	containerName := parts[0]
+	objectName := ""
+	if len(parts) > 1 {
+		objectName = parts[1]
+	}
+
+	switch {
+	case containerName == "":
+		s.storageAccount(w, r, account)
+	case objectName == "":
+		s.storageContainer(w, r, account, containerName)
+	default:
+		s.storageObject(w, r, account, containerName, objectName)
+	}
+}
+
+func (s *Server) storageAuthenticate(w http.ResponseWriter, r *http.Request) {
+	user := strings.SplitN(r.Header.Get("X-Storage-User"), ":", 2)
+	if len(user) != 2 || !strings.HasPrefix(user[0], "Storage-") ||
+		user[1] != s.User || r.Header.Get("X-Storage-Pass") != s.Password {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token := "AUTH_tk" + strings.Replace(newID(), "-", "", -1)
+	s.tokens[token] = &storageSession{
+		account: user[0],
+		issued:  time.Now(),
+	}
+
+	w.Header().Set(storageAuthHeader, token)
+	w.Header().Set("X-Storage-Token", token)
+	w.Header().Set("X-Storage-Url", fmt.Sprintf("%s/v1/%s", s.URL, user[0]))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Serves a request for an object authorized by a temporary URL, signed with one of the
+// temp URL keys of the container instead of an authentication token
+func (s *Server) storageTempURL(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v1/"), "/", 3)
+	if !strings.HasPrefix(r.URL.Path, "/v1/") || len(parts) != 3 || parts[2] == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	account, containerName, objectName := parts[0], parts[1], parts[2]
+
+	expires, err := strconv.ParseInt(query.Get("temp_url_expires"), 10, 64)
+	if err != nil || expires < time.Now().Unix() {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// The signature covers either the object, or a prefix of the names of the objects
+	path := r.URL.Path
+	if prefix, ok := query["temp_url_prefix"]; ok {
+		if !strings.HasPrefix(objectName, prefix[0]) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		path = fmt.Sprintf("prefix:/v1/%s/%s/%s", account, containerName, prefix[0])
+	}
+	body := fmt.Sprintf("%s\n%d\n%s", r.Method, expires, path)
+	if r.Method == http.MethodHead {
+		// A URL signed for a GET also allows a HEAD
+		body = fmt.Sprintf("%s\n%d\n%s", http.MethodGet, expires, path)
+	}
+	if ipRange := query.Get("temp_url_ip_range"); ipRange != "" {
+		if !ipInRange(r.RemoteAddr, ipRange) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body = fmt.Sprintf("ip=%s\n%s", ipRange, body)
+	}
+
+	c, ok := s.containers[account+"/"+containerName]
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	signature := query.Get("temp_url_sig")
+	for _, key := range []string{c.headers.Get("X-Container-Meta-Temp-Url-Key"), c.headers.Get("X-Container-Meta-Temp-Url-Key-2")} {
+		if key == "" {
+			continue
+		}
+		mac := hmac.New(sha1.New, []byte(key))
+		mac.Write([]byte(body))
+		if hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+			s.storageObject(w, r, account, containerName, objectName)
+			return
+		}
+	}
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+// Checks the host of the address is the IP address, or is within the CIDR block, of the range
+func ipInRange(addr, ipRange string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if _, network, err := net.ParseCIDR(ipRange); err == nil {
+		return network.Contains(ip)
+	}
+	return ip.Equal(net.ParseIP(ipRange))
+}
+
+func (s *Server) storageAccount(w http.ResponseWriter, r *http.Request, account string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	names := []string{}
+	for key, c := range s.containers {
+		if strings.HasPrefix(key, account+"/") {
+			names = append(names, c.name)
+		}
+	}
+	sort.Strings(names)
+
+	w.Header().Set("X-Account-Container-Count", strconv.Itoa(len(names)))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		for _, name := range names {
+			fmt.Fprintln(w, name)
+		}
+	}
+}
+
+func (s *Server) storageContainer(w http.ResponseWriter, r *http.Request, account, name string) {
+	key := account + "/" + name
+	c, exists := s.containers[key]
+
+	switch r.Method {
+	case http.MethodPut:
+		status := http.StatusAccepted
+		if !exists {
+			c = newContainer(name)
+			// The storage class can only be set when the container is created
+			c.headers.Set("X-Storage-Class", "Standard")
+			if r.Header.Get("X-Storage-Class") == storageClassArchive {
+				c.headers.Set("X-Storage-Class", storageClassArchive)
+			}
+			s.containers[key] = c
+			status = http.StatusCreated
+		}
+		updateHeaders(c.headers, r.Header, hContainerMetaPrefix, hRemoveContainerMetaPrefix, containerHeaders)
+		w.WriteHeader(status)
+	case http.MethodPost:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		updateHeaders(c.headers, r.Header, hContainerMetaPrefix, hRemoveContainerMetaPrefix, containerHeaders)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet, http.MethodHead:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		s.writeContainerHeaders(w, c)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		s.writeContainerListing(w, r, c)
+	case http.MethodDelete:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		if len(s.liveObjects(c)) > 0 {
+			http.Error(w, "There was a conflict when trying to complete your request.", http.StatusConflict)
+			return
+		}
+		delete(s.containers, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) writeContainerHeaders(w http.ResponseWriter, c *container) {
+	var bytesUsed int
+	objects := s.liveObjects(c)
+	for _, o := range objects {
+		bytesUsed += len(o.data)
+	}
+
+	for k, v := range c.headers {
+		w.Header()[k] = v
+	}
+	w.Header().Set("X-Container-Object-Count", strconv.Itoa(len(objects)))
+	w.Header().Set("X-Container-Bytes-Used", strconv.Itoa(bytesUsed))
+	w.Header().Set("X-Timestamp", fmt.Sprintf("%d.00000", c.created.Unix()))
+	w.Header().Set("X-Trans-Id", "tx"+strings.Replace(newID(), "-", "", -1))
+}
+
+// Writes the objects in the container, filtered by the prefix, delimiter, marker and limit
+// query parameters, as plain text or JSON depending on the format parameter
+func (s *Server) writeContainerListing(w http.ResponseWriter, r *http.Request, c *container) {
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	marker := query.Get("marker")
+	endMarker := query.Get("end_marker")
+	limit := 10000
+	if v := query.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 0 {
+			http.Error(w, "Invalid limit", http.StatusPreconditionFailed)
+			return
+		}
+		limit = l
+	}
+
+	objects := s.liveObjects(c)
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	listing := []map[string]interface{}{}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if len(listing) >= limit {
+			break
+		}
+		if !strings.HasPrefix(name, prefix) || name <= marker || (endMarker != "" && name >= endMarker) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(strings.TrimPrefix(name, prefix), delimiter); i >= 0 {
+				subdir := name[:len(prefix)+i+len(delimiter)]
+				if !seen[subdir] && subdir > marker {
+					seen[subdir] = true
+					listing = append(listing, map[string]interface{}{"subdir": subdir})
+				}
+				continue
+			}
+		}
+		o := objects[name]
+		listing = append(listing, map[string]interface{}{
+			"name":          name,
+			"bytes":         len(o.data),
+			"hash":          strings.Trim(o.headers.Get("Etag"), "\""),
+			"content_type":  o.headers.Get("Content-Type"),
+			"last_modified": o.modified.UTC().Format("2006-01-02T15:04:05.000000"),
+		})
+	}
+
+	if query.Get("format") == "json" {
+		writeJSON(w, "application/json; charset=utf-8", http.StatusOK, listing)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if len(listing) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	for _, entry := range listing {
+		if subdir, ok := entry["subdir"]; ok {
+			fmt.Fprintln(w, subdir)
+			continue
+		}
+		fmt.Fprintln(w, entry["name"])
+	}
+}
+
+func (s *Server) storageObject(w http.ResponseWriter, r *http.Request, account, containerName, name string) {
+	c, ok := s.containers[account+"/"+containerName]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	o, exists := s.liveObjects(c)[name]
+
+	switch r.Method {
+	case http.MethodPut:
+		if r.URL.Query().Get("multipart-manifest") == "put" {
+			s.putStaticLargeObject(w, r, account, c, name)
+			return
+		}
+		s.putObject(w, r, account, c, name)
+	case http.MethodPost:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		if _, ok := r.URL.Query()["restore"]; ok {
+			s.restoreArchivedObject(w, r, c, o)
+			return
+		}
+		// A POST replaces all of the existing metadata of the object, including the manifest and delete-at time
+		for k := range o.headers {
+			if strings.HasPrefix(k, hObjectMetaPrefix) || contains(objectHeaders, k) {
+				o.headers.Del(k)
+			}
+		}
+		updateHeaders(o.headers, r.Header, hObjectMetaPrefix, hRemoveObjectMetaPrefix, append(objectHeaders, "Content-Type"))
+		if err := setDeleteAfter(o.headers, r.Header); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodGet, http.MethodHead:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		if c.headers.Get("X-Storage-Class") == storageClassArchive {
+			status := archiveRestoreStatus(o)
+			if status != "" {
+				w.Header().Set("X-Archive-Restore-Status", status)
+			}
+			if r.Method == http.MethodGet && status != "completed" {
+				http.Error(w, "The object is archived and must be restored before it can be read", http.StatusConflict)
+				return
+			}
+		}
+		if r.URL.Query().Get("multipart-manifest") == "get" && isStaticLargeObject(o) {
+			writeJSON(w, "application/json; charset=utf-8", http.StatusOK, staticManifest(o))
+			return
+		}
+		s.getObject(w, r, account, o)
+	case http.MethodDelete:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		// Deleting a static large object with multipart-manifest=delete also deletes its segments
+		if r.URL.Query().Get("multipart-manifest") == "delete" && isStaticLargeObject(o) {
+			for _, segment := range staticManifest(o) {
+				parts := strings.SplitN(strings.TrimPrefix(segment.Path, "/"), "/", 2)
+				if sc, ok := s.containers[account+"/"+parts[0]]; ok && len(parts) == 2 {
+					delete(sc.objects, parts[1])
+				}
+			}
+		}
+		if c.headers.Get("X-History-Location") != "" {
+			s.archiveObject(account, c, name)
+			delete(c.objects, name)
+		} else {
+			delete(c.objects, name)
+			s.restoreObject(account, c, name)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Starts restoring an object in an archive container, so it can be read
+func (s *Server) restoreArchivedObject(w http.ResponseWriter, r *http.Request, c *container, o *object) {
+	if c.headers.Get("X-Storage-Class") != storageClassArchive {
+		http.Error(w, "Only objects in archive containers can be restored", http.StatusBadRequest)
+		return
+	}
+	// Restoring an object which is already being restored, or can be read, doesn't start another job
+	if archiveRestoreStatus(o) == "" {
+		o.restored = time.Now()
+	}
+	w.Header().Set("X-Archive-Restore-Jobid", fmt.Sprintf("%x", o.restored.UnixNano()))
+	w.Header().Set("X-Archive-Restore-Status", archiveRestoreStatus(o))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Returns the status of the last restore of an archived object, or an empty string if it hasn't
+// been restored or the restored object has expired
+func archiveRestoreStatus(o *object) string {
+	switch elapsed := time.Since(o.restored); {
+	case o.restored.IsZero() || elapsed > archiveRestoreTime+archiveRestoreRetention:
+		return ""
+	case elapsed < archiveRestoreTime:
+		return "in-progress"
+	default:
+		return "completed"
+	}
+}
+
+// Returns the container the previous versions of objects are kept in, if versioning is enabled
+func (s *Server) versionsContainer(account string, c *container) *container {
+	location := c.headers.Get("X-Versions-Location")
+	if location == "" {
+		location = c.headers.Get("X-History-Location")
+	}
+	if location == "" {
+		return nil
+	}
+	return s.containers[account+"/"+location]
+}
+
+// Returns the prefix of the names of the versions of an object, the length of the name
+// as three hex digits, followed by the name
+func versionsPrefix(name string) string {
+	return fmt.Sprintf("%03x%s/", len(name), name)
+}
+
+// Copies the current version of an object to the versions container, before it's overwritten or deleted
+func (s *Server) archiveObject(account string, c *container, name string) {
+	versions := s.versionsContainer(account, c)
+	current, ok := s.liveObjects(c)[name]
+	if versions == nil || !ok {
+		return
+	}
+	headers := make(http.Header)
+	for k, v := range current.headers {
+		headers[k] = append([]string{}, v...)
+	}
+	versionName := fmt.Sprintf("%s%d.%09d", versionsPrefix(name), current.modified.Unix(), current.modified.Nanosecond())
+	versions.objects[versionName] = &object{
+		data:     current.data,
+		headers:  headers,
+		modified: current.modified,
+	}
+}
+
+// Restores the latest previous version of a deleted object, for versioning in the stack mode
+func (s *Server) restoreObject(account string, c *container, name string) {
+	versions := s.versionsContainer(account, c)
+	if versions == nil {
+		return
+	}
+	latest := ""
+	for versionName := range s.liveObjects(versions) {
+		if strings.HasPrefix(versionName, versionsPrefix(name)) && versionName > latest {
+			latest = versionName
+		}
+	}
+	if latest == "" {
+		return
+	}
+	c.objects[name] = versions.objects[latest]
+	delete(versions.objects, latest)
+}
+
+// A segment of a static large object manifest
+type staticSegment struct {
+	Path      string `json:"path"`
+	Etag      string `json:"etag"`
+	SizeBytes int    `json:"size_bytes"`
+}
+
+// Stores a static large object manifest, after checking each of its segments exists and
+// matches the etag and size given for it
+func (s *Server) putStaticLargeObject(w http.ResponseWriter, r *http.Request, account string, c *container, name string) {
+	var segments []staticSegment
+	if err := json.NewDecoder(r.Body).Decode(&segments); err != nil || len(segments) == 0 {
+		http.Error(w, "Invalid manifest", http.StatusBadRequest)
+		return
+	}
+	for _, segment := range segments {
+		parts := strings.SplitN(strings.TrimPrefix(segment.Path, "/"), "/", 2)
+		if len(parts) != 2 {
+			http.Error(w, fmt.Sprintf("Invalid segment path %s", segment.Path), http.StatusBadRequest)
+			return
+		}
+		sc, ok := s.containers[account+"/"+parts[0]]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Segment %s not found", segment.Path), http.StatusBadRequest)
+			return
+		}
+		so, ok := s.liveObjects(sc)[parts[1]]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Segment %s not found", segment.Path), http.StatusBadRequest)
+			return
+		}
+		if (segment.Etag != "" && segment.Etag != strings.Trim(so.headers.Get("Etag"), "\"")) ||
+			(segment.SizeBytes != 0 && segment.SizeBytes != len(so.data)) {
+			http.Error(w, fmt.Sprintf("Segment %s does not match the manifest", segment.Path), http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, _ := json.Marshal(segments)
+	headers := make(http.Header)
+	headers.Set("Content-Type", defaultContentType)
+	if v := r.Header.Get("Content-Type"); v != "" {
+		headers.Set("Content-Type", v)
+	}
+	updateHeaders(headers, r.Header, hObjectMetaPrefix, hRemoveObjectMetaPrefix, objectHeaders)
+	if err := setDeleteAfter(headers, r.Header); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	headers.Set("X-Static-Large-Object", "True")
+	headers.Set("Etag", staticManifestEtag(segments))
+
+	s.archiveObject(account, c, name)
+	c.objects[name] = &object{
+		data:     data,
+		headers:  headers,
+		modified: time.Now(),
+	}
+
+	w.Header().Set("Etag", headers.Get("Etag"))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func isStaticLargeObject(o *object) bool {
+	return o.headers.Get("X-Static-Large-Object") == "True"
+}
+
+func staticManifest(o *object) []staticSegment {
+	var segments []staticSegment
+	_ = json.Unmarshal(o.data, &segments)
+	return segments
+}
+
+// The etag of a static large object is the hash of the etags of its segments
+func staticManifestEtag(segments []staticSegment) string {
+	hash := md5.New()
+	for _, segment := range segments {
+		hash.Write([]byte(segment.Etag))
+	}
+	return fmt.Sprintf("\"%s\"", hex.EncodeToString(hash.Sum(nil)))
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, account string, c *container, name string) {
+	var data []byte
+	headers := make(http.Header)
+
+	if source := r.Header.Get("X-Copy-From"); source != "" {
+		parts := strings.SplitN(strings.TrimPrefix(source, "/"), "/", 2)
+		if len(parts) != 2 {
+			http.Error(w, "X-Copy-From header must be of the form <container name>/<object name>", http.StatusPreconditionFailed)
+			return
+		}
+		sc, ok := s.containers[account+"/"+parts[0]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		so, ok := s.liveObjects(sc)[parts[1]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data = append([]byte{}, s.objectData(account, so)...)
+		for k, v := range so.headers {
+			headers[k] = append([]string{}, v...)
+		}
+		headers.Del("X-Object-Manifest")
+		headers.Del("X-Static-Large-Object")
+	} else {
+		var err error
+		if data, err = ioutil.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		headers.Set("Content-Type", defaultContentType)
+	}
+
+	sum := md5.Sum(data)
+	etag := hex.EncodeToString(sum[:])
+	if expected := strings.Trim(r.Header.Get("Etag"), "\""); expected != "" && expected != etag {
+		http.Error(w, "Unprocessable Entity", 422)
+		return
+	}
+
+	if v := r.Header.Get("Content-Type"); v != "" {
+		headers.Set("Content-Type", v)
+	}
+	updateHeaders(headers, r.Header, hObjectMetaPrefix, hRemoveObjectMetaPrefix, objectHeaders)
+	if err := setDeleteAfter(headers, r.Header); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	headers.Set("Etag", etag)
+
+	s.archiveObject(account, c, name)
+	c.objects[name] = &object{
+		data:     data,
+		headers:  headers,
+		modified: time.Now(),
+	}
+
+	w.Header().Set("Etag", etag)
+	w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, account string, o *object) {
+	data := s.objectData(account, o)
+
+	for k, v := range o.headers {
+		w.Header()[k] = v
+	}
+	if manifest := o.headers.Get("X-Object-Manifest"); manifest != "" {
+		// The etag of a dynamic large object is the hash of the etags of its segments
+		hash := md5.New()
+		for _, segment := range s.manifestSegments(account, manifest) {
+			hash.Write([]byte(strings.Trim(segment.headers.Get("Etag"), "\"")))
+		}
+		w.Header().Set("Etag", fmt.Sprintf("\"%s\"", hex.EncodeToString(hash.Sum(nil))))
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Last-Modified", o.modified.UTC().Format(http.TimeFormat))
+	w.Header().Set("X-Timestamp", fmt.Sprintf("%d.00000", o.modified.Unix()))
+	w.Header().Set("X-Trans-Id", "tx"+strings.Replace(newID(), "-", "", -1))
+
+	status := http.StatusOK
+	if v := r.Header.Get("Range"); v != "" {
+		start, end, ok := parseRange(v, len(data))
+		if !ok {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(data)))
+			http.Error(w, "Requested Range Not Satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+		data = data[start:end]
+		status = http.StatusPartialContent
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+// Returns the content of the object, following a static or dynamic large object manifest
+func (s *Server) objectData(account string, o *object) []byte {
+	if isStaticLargeObject(o) {
+		var data []byte
+		for _, segment := range staticManifest(o) {
+			parts := strings.SplitN(strings.TrimPrefix(segment.Path, "/"), "/", 2)
+			if sc, ok := s.containers[account+"/"+parts[0]]; ok && len(parts) == 2 {
+				if so, ok := s.liveObjects(sc)[parts[1]]; ok {
+					data = append(data, so.data...)
+				}
+			}
+		}
+		return data
+	}
+	manifest := o.headers.Get("X-Object-Manifest")
+	if manifest == "" {
+		return o.data
+	}
+	var data []byte
+	for _, segment := range s.manifestSegments(account, manifest) {
+		data = append(data, segment.data...)
+	}
+	return data
+}
+
+// Returns the segments referenced by a dynamic large object manifest of the form
+// <container>/<prefix>, in the order they are concatenated
+func (s *Server) manifestSegments(account, manifest string) []*object {
+	parts := strings.SplitN(manifest, "/", 2)
+	c, ok := s.containers[account+"/"+parts[0]]
+	if !ok {
+		return nil
+	}
+	prefix := ""
+	if len(parts) > 1 {
+		prefix = parts[1]
+	}
+
+	objects := s.liveObjects(c)
+	names := []string{}
+	for name := range objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	segments := make([]*object, 0, len(names))
+	for _, name := range names {
+		segments = append(segments, objects[name])
+	}
+	return segments
+}
+
+// Returns the objects in the container, removing any whose delete-at time has passed
+func (s *Server) liveObjects(c *container) map[string]*object {
+	now := time.Now().Unix()
+	for name, o := range c.objects {
+		if v := o.headers.Get("X-Delete-At"); v != "" {
+			if at, err := strconv.ParseInt(v, 10, 64); err == nil && at <= now {
+				delete(c.objects, name)
+			}
+		}
+	}
+	return c.objects
+}
+
+// Applies the metadata headers of a request to the stored headers. Setting a header to
+// an empty value, or sending the matching X-Remove- header, removes it.
+func updateHeaders(stored, request http.Header, metaPrefix, removePrefix string, explicit []string) {
+	for k := range request {
+		v := request.Get(k)
+		switch {
+		case strings.HasPrefix(k, removePrefix):
+			stored.Del(metaPrefix + strings.TrimPrefix(k, removePrefix))
+		case strings.HasPrefix(k, "X-Remove-") && contains(explicit, "X-"+strings.TrimPrefix(k, "X-Remove-")):
+			stored.Del("X-" + strings.TrimPrefix(k, "X-Remove-"))
+		case strings.HasPrefix(k, metaPrefix) || contains(explicit, k):
+			if v == "" {
+				stored.Del(k)
+			} else {
+				stored.Set(k, v)
+			}
+		}
+	}
+}
+
+// Converts the X-Delete-After header of a request, in seconds, to the X-Delete-At time of the object
+func setDeleteAfter(stored, request http.Header) error {
+	v := request.Get("X-Delete-After")
+	if v == "" {
+		return nil
+	}
+	seconds, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("Non-integer X-Delete-After")
+	}
+	stored.Set("X-Delete-At", strconv.FormatInt(time.Now().Add(time.Duration(seconds)*time.Second).Unix(), 10))
+	return nil
+}
+
+// Parses a single byte range header value, returning the half open interval it selects
+func parseRange(header string, size int) (int, int, bool) {
+	spec := strings.TrimPrefix(header, "bytes=")
+	if spec == header || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	if parts[0] == "" {
+		// A suffix range returns the last n bytes
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size, true
+	}
+
+	start, err := strconv.Atoi(parts[0])
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+	end := size
+	if parts[1] != "" {
+		last, err := strconv.Atoi(parts[1])
+		if err != nil || last < start {
+			return 0, 0, false
+		}
+		if last+1 < size {
+			end = last + 1
+		}
+	}
+	return start, end, true
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package opc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/opc"
+	"github.com/hashicorp/go-oracle-terraform/storage"
+)
+
+// The most objects the API returns in a single container listing
+const storageListPageSize = 10000
+
+// storageAPIClient makes requests to the Object Storage Classic API that the go-oracle-terraform
+// storage client doesn't support, sharing the authenticated session of the storage client.
+type storageAPIClient struct {
+	httpClient *http.Client
+	endpoint   *url.URL
+	// The storage account, e.g. Storage-domain
+	account   string
+	userAgent string
+}
+
+// storageListObjectsInput filters the objects returned by a container listing
+type storageListObjectsInput struct {
+	Container string
+	// Only return objects whose names start with this prefix
+	Prefix string
+	// Roll up the names containing the delimiter after the prefix into common prefixes
+	Delimiter string
+	// Only return objects whose names sort after the marker
+	Marker string
+	// The maximum number of objects and common prefixes to return. Zero returns them all.
+	Limit int
+}
+
+// storageObjectSummary is an entry of a JSON container listing. Common prefixes only have Subdir set.
+type storageObjectSummary struct {
+	Name         string `json:"name"`
+	Bytes        int    `json:"bytes"`
+	Hash         string `json:"hash"`
+	ContentType  string `json:"content_type"`
+	LastModified string `json:"last_modified"`
+	Subdir       string `json:"subdir"`
+}
+
+// Returns the path of a container or object, e.g. /v1/Storage-domain/container/object
+func (c *storageAPIClient) path(container, object string) string {
+	path := fmt.Sprintf("/v1/%s/%s", c.account, container)
+	if object != "" {
+		path = fmt.Sprintf("%s/%s", path, object)
+	}
+	return path
+}
+
+// do makes a request to the storage API, returning an error for any non-2xx response.
+// The caller must close the body of the returned response.
+func (c *storageAPIClient) do(method, path string, query url.Values, headers map[string]string, body io.Reader) (*http.Response, error) {
+	reqURL := c.endpoint.ResolveReference(&url.URL{Path: path, RawQuery: query.Encode()})
+
+	req, err := http.NewRequest(method, reqURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	// Section readers are used to upload segments of files, and can be re-read if the request is retried
+	if section, ok := body.(*io.SectionReader); ok {
+		req.ContentLength = section.Size()
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(io.NewSectionReader(section, 0, section.Size())), nil
+		}
+		if section.Size() == 0 {
+			req.Body = http.NoBody
+		}
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	log.Printf("[DEBUG] Storage request: %s %s", method, reqURL)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		message, _ := ioutil.ReadAll(resp.Body)
+		return nil, &opc.OracleError{StatusCode: resp.StatusCode, Message: string(message)}
+	}
+	return resp, nil
+}
+
+// ListObjects lists the objects in a container, following the listing across pages until
+// the input's limit is reached or there are no more objects
+func (c *storageAPIClient) ListObjects(input *storageListObjectsInput) ([]storageObjectSummary, error) {
+	result := make([]storageObjectSummary, 0)
+	marker := input.Marker
+	for {
+		pageSize := storageListPageSize
+		if input.Limit > 0 && input.Limit-len(result) < pageSize {
+			pageSize = input.Limit - len(result)
+		}
+
+		query := url.Values{}
+		query.Set("format", "json")
+		query.Set("limit", strconv.Itoa(pageSize))
+		if input.Prefix != "" {
+			query.Set("prefix", input.Prefix)
+		}
+		if input.Delimiter != "" {
+			query.Set("delimiter", input.Delimiter)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		page, err := c.listObjectsPage(input.Container, query)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, page...)
+
+		if len(page) < pageSize || (input.Limit > 0 && len(result) >= input.Limit) {
+			return result, nil
+		}
+		last := page[len(page)-1]
+		marker = last.Name
+		if last.Subdir != "" {
+			marker = last.Subdir
+		}
+	}
+}
+
+func (c *storageAPIClient) listObjectsPage(container string, query url.Values) ([]storageObjectSummary, error) {
+	resp, err := c.do(http.MethodGet, c.path(container, ""), query, map[string]string{"Accept": "application/json"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// An empty container can be returned as a 204 with no body
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var page []storageObjectSummary
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return page, nil
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("Error parsing the listing of container %s: %s", container, err)
+	}
+	return page, nil
+}
+
+// storageObjectContent is the content of an object, along with the headers describing it
+type storageObjectContent struct {
+	// The content of the object, which the caller must close
+	Body          io.ReadCloser
+	ContentLength int64
+	// The part of the object returned for a range request, e.g. bytes 0-99/1000
+	ContentRange string
+	ContentType  string
+	Etag         string
+	LastModified string
+	Metadata     map[string]string
+}
+
+// GetObjectContent downloads the content of an object, or the part of it selected by the input's Range
+func (c *storageAPIClient) GetObjectContent(input *storage.GetObjectInput) (*storageObjectContent, error) {
+	container, name := input.Container, input.Name
+	if input.ID != "" {
+		container, name = parseStorageObjectID(input.ID)
+	}
+	if container == "" || name == "" {
+		return nil, fmt.Errorf("Either ID or Name and Container must be set to get the content of an object")
+	}
+
+	headers := make(map[string]string)
+	if input.Range != "" {
+		headers["Range"] = input.Range
+	}
+	if input.Newest {
+		headers["X-Newest"] = "true"
+	}
+
+	resp, err := c.do(http.MethodGet, c.path(container, name), nil, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &storageObjectContent{
+		Body:          resp.Body,
+		ContentLength: resp.ContentLength,
+		ContentRange:  resp.Header.Get("Content-Range"),
+		ContentType:   resp.Header.Get("Content-Type"),
+		Etag:          strings.Trim(resp.Header.Get("Etag"), "\""),
+		LastModified:  resp.Header.Get("Last-Modified"),
+		Metadata:      make(map[string]string),
+	}
+	// Matches the metadata returned by the storage client's GetObject
+	for header, value := range resp.Header {
+		if strings.HasPrefix(header, "X-Object-Meta-") {
+			content.Metadata[strings.TrimPrefix(header, "X-Object-Meta-")] = strings.Join(value, " ")
+		}
+	}
+	return content, nil
+}
+
+// CreateContainer creates the container if it doesn't already exist. Existing containers are left unchanged,
+// other than any headers given. The storage class, set by X-Storage-Class, can only be set by creating the container.
+func (c *storageAPIClient) CreateContainer(container string, headers map[string]string) error {
+	resp, err := c.do(http.MethodPut, c.path(container, ""), nil, headers, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetContainerHeaders returns the headers of a container, including those the storage client doesn't read
+func (c *storageAPIClient) GetContainerHeaders(container string) (http.Header, error) {
+	resp, err := c.do(http.MethodHead, c.path(container, ""), nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp.Header, nil
+}
+
+// UpdateContainerHeaders sets the headers of a container. Unlike objects, only the headers given are changed.
+// Headers are removed by setting the matching X-Remove- header, e.g. X-Remove-Versions-Location.
+func (c *storageAPIClient) UpdateContainerHeaders(container string, headers map[string]string) error {
+	resp, err := c.do(http.MethodPost, c.path(container, ""), nil, headers, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PutObject uploads the body as the content of an object, returning the etag of the stored object.
+// Set the Etag header to have the API check the content wasn't corrupted on the way.
+func (c *storageAPIClient) PutObject(container, name string, headers map[string]string, body io.Reader) (string, error) {
+	resp, err := c.do(http.MethodPut, c.path(container, name), nil, headers, body)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return strings.Trim(resp.Header.Get("Etag"), "\""), nil
+}
+
+// PutStaticLargeObject creates a static large object from segments which have already been uploaded
+func (c *storageAPIClient) PutStaticLargeObject(container, name string, headers map[string]string, segments []storageSegment) (string, error) {
+	manifest, err := json.Marshal(segments)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("multipart-manifest", "put")
+
+	resp, err := c.do(http.MethodPut, c.path(container, name), query, headers, bytes.NewReader(manifest))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return strings.Trim(resp.Header.Get("Etag"), "\""), nil
+}
+
+// UpdateObjectMetadata updates the headers of an object without uploading its content again. The API
+// replaces all of the metadata of the object, so every header to keep, e.g. X-Object-Manifest, must be given.
+func (c *storageAPIClient) UpdateObjectMetadata(container, name string, headers map[string]string) error {
+	resp, err := c.do(http.MethodPost, c.path(container, name), nil, headers, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetObjectHeaders returns the headers of an object, including those the storage client doesn't read
+func (c *storageAPIClient) GetObjectHeaders(container, name string) (http.Header, error) {
+	resp, err := c.do(http.MethodHead, c.path(container, name), nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp.Header, nil
+}
+
+// RestoreObject starts a job to restore an object in an archive container, returning the ID of the job.
+// The object can be read once the X-Archive-Restore-Status of the object is completed.
+func (c *storageAPIClient) RestoreObject(container, name string) (string, error) {
+	query := url.Values{}
+	query.Set("restore", "")
+	resp, err := c.do(http.MethodPost, c.path(container, name), query, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("X-Archive-Restore-Jobid"), nil
+}
+
+// DeleteObject deletes an object. Any query, e.g. multipart-manifest=delete, is passed to the API.
+func (c *storageAPIClient) DeleteObject(container, name string, query url.Values) error {
+	resp, err := c.do(http.MethodDelete, c.path(container, name), query, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,261 @@
+package opc
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	storageManifestStatic  = "static"
+	storageManifestDynamic = "dynamic"
+
+	// The smallest and largest segments the API accepts. The last segment may be smaller.
+	storageMinSegmentSize = 1024 * 1024
+	storageMaxSegmentSize = 5 * 1024 * 1024 * 1024
+	// The most segments a static large object manifest can list
+	storageMaxManifestSegments = 1000
+)
+
+// The name of a segment after the object name, e.g. 10737418240/1073741824/00000003
+var storageSegmentNameRegexp = regexp.MustCompile(`^\d+/\d+/\d{8}$`)
+
+// storageSegment is an uploaded segment of a large object, as listed in a static large object manifest
+type storageSegment struct {
+	Path      string `json:"path"`
+	Etag      string `json:"etag"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// storageSegmentedUploadInput describes a file to upload as a large object, made up of segments
+// stored in a separate container and joined together by a manifest object
+type storageSegmentedUploadInput struct {
+	Container string
+	Name      string
+	// The container the segments are uploaded to, created if it doesn't exist
+	SegmentContainer string
+	SegmentSize      int64
+	// The number of segments to upload at once
+	Parallelism int
+	// Either static or dynamic
+	ManifestType string
+	// The headers of the manifest object, e.g. Content-Type and X-Object-Meta-*
+	Headers map[string]string
+}
+
+// Returns the prefix of the segment names. The segments are named after the object and the sizes of the
+// file and segments, so an interrupted upload of the same file can be resumed.
+func storageSegmentPrefix(name string, size, segmentSize int64) string {
+	return fmt.Sprintf("%s/%d/%d/", name, size, segmentSize)
+}
+
+// Returns the number of segments the file is uploaded in. An empty file is uploaded as a single, empty segment.
+func storageSegmentCount(size, segmentSize int64) int {
+	count := int((size + segmentSize - 1) / segmentSize)
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// Checks a static large object manifest can list every segment of the file
+func checkStorageSegmentCount(size, segmentSize int64, manifestType string) error {
+	if manifestType == storageManifestDynamic {
+		return nil
+	}
+	if count := storageSegmentCount(size, segmentSize); count > storageMaxManifestSegments {
+		return fmt.Errorf("The file of %d bytes is %d segments of %d bytes, more than the %d a static manifest can list. "+
+			"Increase segment_size, or set manifest_type to dynamic", size, count, segmentSize, storageMaxManifestSegments)
+	}
+	return nil
+}
+
+// UploadSegmented uploads the file as a large object. Segments which were already uploaded with the
+// same content, e.g. by an earlier, interrupted upload, are not uploaded again. Once the manifest is
+// created, the segments of earlier uploads of the object with a different file or segment size are deleted.
+func (c *storageAPIClient) UploadSegmented(input *storageSegmentedUploadInput, file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	prefix := storageSegmentPrefix(input.Name, size, input.SegmentSize)
+
+	if err := checkStorageSegmentCount(size, input.SegmentSize, input.ManifestType); err != nil {
+		return err
+	}
+	count := storageSegmentCount(size, input.SegmentSize)
+
+	if err := c.CreateContainer(input.SegmentContainer, nil); err != nil {
+		return fmt.Errorf("Error creating segment container %s: %s", input.SegmentContainer, err)
+	}
+
+	existing := make(map[string]string)
+	uploaded, err := c.ListObjects(&storageListObjectsInput{
+		Container: input.SegmentContainer,
+		Prefix:    prefix,
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing segments in %s: %s", input.SegmentContainer, err)
+	}
+	for _, segment := range uploaded {
+		existing[segment.Name] = segment.Hash
+	}
+
+	segments := make([]storageSegment, count)
+	uploadSegment := func(i int) error {
+		offset := int64(i) * input.SegmentSize
+		length := input.SegmentSize
+		if offset+length > size {
+			length = size - offset
+		}
+		name := fmt.Sprintf("%s%08d", prefix, i)
+
+		hash := md5.New()
+		if _, err := io.Copy(hash, io.NewSectionReader(file, offset, length)); err != nil {
+			return fmt.Errorf("Error reading segment %d of %s: %s", i, file.Name(), err)
+		}
+		etag := hex.EncodeToString(hash.Sum(nil))
+		segments[i] = storageSegment{
+			Path:      fmt.Sprintf("/%s/%s", input.SegmentContainer, name),
+			Etag:      etag,
+			SizeBytes: length,
+		}
+
+		if existing[name] == etag {
+			log.Printf("[DEBUG] Segment %s/%s is already uploaded", input.SegmentContainer, name)
+			return nil
+		}
+		log.Printf("[DEBUG] Uploading segment %s/%s", input.SegmentContainer, name)
+		headers := map[string]string{"Etag": etag}
+		if _, err := c.PutObject(input.SegmentContainer, name, headers, io.NewSectionReader(file, offset, length)); err != nil {
+			return fmt.Errorf("Error uploading segment %s/%s: %s", input.SegmentContainer, name, err)
+		}
+		return nil
+	}
+
+	// The segments uploaded before a failure are kept to resume from
+	if err := storageParallel(count, input.Parallelism, uploadSegment); err != nil {
+		return err
+	}
+
+	if input.ManifestType == storageManifestDynamic {
+		headers := make(map[string]string)
+		for k, v := range input.Headers {
+			headers[k] = v
+		}
+		headers["X-Object-Manifest"] = fmt.Sprintf("%s/%s", input.SegmentContainer, prefix)
+		_, err = c.PutObject(input.Container, input.Name, headers, io.NewSectionReader(file, 0, 0))
+	} else {
+		_, err = c.PutStaticLargeObject(input.Container, input.Name, input.Headers, segments)
+	}
+	if err != nil {
+		return fmt.Errorf("Error creating the manifest of %s/%s: %s", input.Container, input.Name, err)
+	}
+
+	// The object is already uploaded, so segments which can't be deleted are left behind
+	if err := c.deleteStaleSegments(input.SegmentContainer, input.Name, prefix); err != nil {
+		log.Printf("[WARN] Error deleting stale segments of %s/%s: %s", input.Container, input.Name, err)
+	}
+	return nil
+}
+
+// Deletes the segments of the object outside the prefix of the current upload, which were uploaded for
+// an earlier file, or with a different segment size. Segments of other objects whose names start with
+// the name of this object are left alone.
+func (c *storageAPIClient) deleteStaleSegments(segmentContainer, name, prefix string) error {
+	segments, err := c.ListObjects(&storageListObjectsInput{
+		Container: segmentContainer,
+		Prefix:    name + "/",
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing segments in %s: %s", segmentContainer, err)
+	}
+	for _, segment := range segments {
+		if strings.HasPrefix(segment.Name, prefix) || !storageSegmentNameRegexp.MatchString(strings.TrimPrefix(segment.Name, name+"/")) {
+			continue
+		}
+		log.Printf("[DEBUG] Deleting stale segment %s/%s", segmentContainer, segment.Name)
+		if err := c.DeleteObject(segmentContainer, segment.Name, nil); err != nil {
+			return fmt.Errorf("Error deleting segment %s/%s: %s", segmentContainer, segment.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeleteSegmented deletes a large object along with its segments. The segments of a dynamic large
+// object are found from its manifest, e.g. segment-container/prefix.
+func (c *storageAPIClient) DeleteSegmented(container, name, manifestType, objectManifest string) error {
+	if manifestType != storageManifestDynamic {
+		query := url.Values{}
+		query.Set("multipart-manifest", "delete")
+		return c.DeleteObject(container, name, query)
+	}
+
+	if err := c.DeleteObject(container, name, nil); err != nil {
+		return err
+	}
+	parts := strings.SplitN(objectManifest, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil
+	}
+	segments, err := c.ListObjects(&storageListObjectsInput{
+		Container: parts[0],
+		Prefix:    parts[1],
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing segments in %s: %s", parts[0], err)
+	}
+	for _, segment := range segments {
+		if err := c.DeleteObject(parts[0], segment.Name, nil); err != nil {
+			return fmt.Errorf("Error deleting segment %s/%s: %s", parts[0], segment.Name, err)
+		}
+	}
+	return nil
+}
+
+// Calls fn for each of the numbers up to count, in parallel up to the given number of calls at once,
+// returning the first error. No more calls are started after an error.
+func storageParallel(count, parallelism int, fn func(i int) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	jobs := make(chan int)
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(i); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,154 @@
+package opc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-oracle-terraform/storage"
+	"github.com/terraform-providers/terraform-provider-opc/opc/simulator"
+)
+
+// Counts the segments uploaded through the transport
+type segmentCountingTransport struct {
+	mu        sync.Mutex
+	uploads   int
+	transport http.RoundTripper
+}
+
+func (t *segmentCountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPut && strings.Contains(req.URL.Path, "_segments/") {
+		t.mu.Lock()
+		t.uploads++
+		t.mu.Unlock()
+	}
+	return t.transport.RoundTrip(req)
+}
+
+func TestStorageAPIClient_uploadSegmented(t *testing.T) {
+	s := simulator.New()
+	defer s.Close()
+
+	client, err := testSessionConfig(s).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	storageClient, err := client.getStorageAPIClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter := &segmentCountingTransport{transport: storageClient.httpClient.Transport}
+	storageClient.httpClient = newHTTPClient(counter)
+
+	// Two and a half segments
+	data := make([]byte, storageMinSegmentSize*5/2)
+	rand.Read(data)
+	file, err := ioutil.TempFile("", "opc-segmented-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := storageClient.CreateContainer("uploads", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, manifestType := range []string{storageManifestStatic, storageManifestDynamic} {
+		counter.uploads = 0
+		input := &storageSegmentedUploadInput{
+			Container:        "uploads",
+			Name:             "image-" + manifestType + ".tar.gz",
+			SegmentContainer: "uploads_segments",
+			SegmentSize:      storageMinSegmentSize,
+			Parallelism:      2,
+			ManifestType:     manifestType,
+			Headers:          map[string]string{"Content-Type": "application/gzip"},
+		}
+		if err := storageClient.UploadSegmented(input, file); err != nil {
+			t.Fatalf("Error uploading %s large object: %s", manifestType, err)
+		}
+		if counter.uploads != 3 {
+			t.Fatalf("Expected 3 segments to be uploaded, got %d", counter.uploads)
+		}
+
+		content, err := storageClient.GetObjectContent(&storage.GetObjectInput{Container: "uploads", Name: input.Name})
+		if err != nil {
+			t.Fatalf("Error reading %s large object: %s", manifestType, err)
+		}
+		body, _ := ioutil.ReadAll(content.Body)
+		content.Body.Close()
+		if !bytes.Equal(body, data) {
+			t.Fatalf("Expected the %s large object to have the content of the file", manifestType)
+		}
+		if content.ContentType != "application/gzip" {
+			t.Fatalf("Expected content type application/gzip, got %q", content.ContentType)
+		}
+
+		// Uploading the same file again resumes from the segments already uploaded
+		counter.uploads = 0
+		if err := storageClient.UploadSegmented(input, file); err != nil {
+			t.Fatalf("Error resuming %s large object upload: %s", manifestType, err)
+		}
+		if counter.uploads != 0 {
+			t.Fatalf("Expected no segments to be uploaded again, got %d", counter.uploads)
+		}
+
+		// Uploading with a different segment size deletes the segments of the earlier upload, but
+		// leaves objects which aren't segments in place
+		notes := input.Name + "/notes.txt"
+		if _, err := storageClient.PutObject("uploads_segments", notes, nil, strings.NewReader("notes")); err != nil {
+			t.Fatal(err)
+		}
+		input.SegmentSize = 2 * storageMinSegmentSize
+		if err := storageClient.UploadSegmented(input, file); err != nil {
+			t.Fatalf("Error uploading %s large object with a new segment size: %s", manifestType, err)
+		}
+		segments, err := storageClient.ListObjects(&storageListObjectsInput{Container: "uploads_segments", Prefix: input.Name + "/"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) != 3 {
+			t.Fatalf("Expected the 2 new segments and the notes to be kept, got %+v", segments)
+		}
+		if err := storageClient.DeleteObject("uploads_segments", notes, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		manifest := ""
+		if manifestType == storageManifestDynamic {
+			manifest = "uploads_segments/" + storageSegmentPrefix(input.Name, int64(len(data)), input.SegmentSize)
+		}
+		if err := storageClient.DeleteSegmented("uploads", input.Name, manifestType, manifest); err != nil {
+			t.Fatalf("Error deleting %s large object: %s", manifestType, err)
+		}
+		segments, err = storageClient.ListObjects(&storageListObjectsInput{Container: "uploads_segments"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) != 0 {
+			t.Fatalf("Expected the segments of the %s large object to be deleted, got %+v", manifestType, segments)
+		}
+	}
+}
+
+func TestCheckStorageSegmentCount(t *testing.T) {
+	if err := checkStorageSegmentCount(1000*storageMinSegmentSize, storageMinSegmentSize, storageManifestStatic); err != nil {
+		t.Fatalf("Expected 1000 segments to be allowed, got %s", err)
+	}
+	err := checkStorageSegmentCount(1000*storageMinSegmentSize+1, storageMinSegmentSize, storageManifestStatic)
+	if err == nil || !strings.Contains(err.Error(), "is 1001 segments") {
+		t.Fatalf("Expected an error for 1001 segments, got %v", err)
+	}
+	// Dynamic manifests don't list their segments
+	if err := checkStorageSegmentCount(1000*storageMinSegmentSize+1, storageMinSegmentSize, storageManifestDynamic); err != nil {
+		t.Fatalf("Expected any number of segments for a dynamic manifest, got %s", err)
+	}
+}
@@ -0,0 +1,288 @@
+package opc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mitchellh/go-homedir"
+)
+
+// The TLS versions accepted by min_tls_version
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// transportConfig holds the TLS and proxy settings of the HTTP transport used for an API.
+// Empty values are left at the defaults of the transport.
+type transportConfig struct {
+	// Certificates of the certificate authorities to trust, as well as the system's
+	CAFile string
+	CAPEM  string
+	// The client certificate and key to authenticate with
+	ClientCertFile string
+	ClientCertPEM  string
+	ClientKeyFile  string
+	ClientKeyPEM   string
+	// The minimum TLS version, e.g. 1.2
+	MinTLSVersion string
+	// The proxy to use for every request, instead of the proxy from the environment
+	ProxyURL string
+	// Comma separated hosts, domains, IP addresses and CIDR blocks which are reached directly, rather than through the proxy
+	NoProxy string
+}
+
+// Returns the schema of the transport settings. The provider level settings can also be set with
+// environment variables, the per service overrides can't.
+func transportSchema(withEnv bool) map[string]*schema.Schema {
+	envDefault := func(key string) schema.SchemaDefaultFunc {
+		if !withEnv {
+			return nil
+		}
+		return schema.EnvDefaultFunc(key, nil)
+	}
+	return map[string]*schema.Schema{
+		"ca_file": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			DefaultFunc: envDefault("OPC_CA_FILE"),
+			Description: "Path of a PEM file of certificate authorities to trust, as well as the system's",
+		},
+		"ca_pem": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "PEM encoded certificate authorities to trust, as well as the system's",
+		},
+		"client_cert_file": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			DefaultFunc:   envDefault("OPC_CLIENT_CERT_FILE"),
+			ConflictsWith: transportConflicts(withEnv, "client_cert_pem"),
+			Description:   "Path of a PEM file of the client certificate to authenticate with",
+		},
+		"client_cert_pem": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: transportConflicts(withEnv, "client_cert_file"),
+			Description:   "PEM encoded client certificate to authenticate with",
+		},
+		"client_key_file": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			DefaultFunc:   envDefault("OPC_CLIENT_KEY_FILE"),
+			ConflictsWith: transportConflicts(withEnv, "client_key_pem"),
+			Description:   "Path of a PEM file of the client certificate's private key",
+		},
+		"client_key_pem": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Sensitive:     true,
+			ConflictsWith: transportConflicts(withEnv, "client_key_file"),
+			Description:   "PEM encoded private key of the client certificate",
+		},
+		"min_tls_version": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			DefaultFunc:  envDefault("OPC_MIN_TLS_VERSION"),
+			ValidateFunc: validation.StringInSlice([]string{"1.0", "1.1", "1.2", "1.3"}, false),
+			Description:  "The minimum TLS version to accept, e.g. 1.2",
+		},
+		"proxy_url": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			DefaultFunc: envDefault("OPC_PROXY_URL"),
+			Description: "The proxy to send requests through, instead of the proxy set by the HTTPS_PROXY environment variable",
+		},
+		"no_proxy": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			DefaultFunc: envDefault("OPC_NO_PROXY"),
+			Description: "Comma separated hosts, domains, IP addresses and CIDR blocks which are reached directly, rather than through proxy_url or the proxy set by the HTTPS_PROXY environment variable",
+		},
+	}
+}
+
+// ConflictsWith keys are absolute, so the conflicts within the per service blocks aren't checked
+func transportConflicts(withEnv bool, key string) []string {
+	if !withEnv {
+		return nil
+	}
+	return []string{key}
+}
+
+// Reads the transport settings from the provider, or from a per service block
+func readTransportConfig(get func(key string) interface{}) transportConfig {
+	return transportConfig{
+		CAFile:         get("ca_file").(string),
+		CAPEM:          get("ca_pem").(string),
+		ClientCertFile: get("client_cert_file").(string),
+		ClientCertPEM:  get("client_cert_pem").(string),
+		ClientKeyFile:  get("client_key_file").(string),
+		ClientKeyPEM:   get("client_key_pem").(string),
+		MinTLSVersion:  get("min_tls_version").(string),
+		ProxyURL:       get("proxy_url").(string),
+		NoProxy:        get("no_proxy").(string),
+	}
+}
+
+// Returns the settings, with any set in the override replacing them
+func (t transportConfig) merge(override transportConfig) transportConfig {
+	merged := t
+	set := func(value *string, override string) {
+		if override != "" {
+			*value = override
+		}
+	}
+	set(&merged.CAFile, override.CAFile)
+	set(&merged.CAPEM, override.CAPEM)
+	set(&merged.ClientCertFile, override.ClientCertFile)
+	set(&merged.ClientCertPEM, override.ClientCertPEM)
+	set(&merged.ClientKeyFile, override.ClientKeyFile)
+	set(&merged.ClientKeyPEM, override.ClientKeyPEM)
+	set(&merged.MinTLSVersion, override.MinTLSVersion)
+	set(&merged.ProxyURL, override.ProxyURL)
+	set(&merged.NoProxy, override.NoProxy)
+	return merged
+}
+
+// Builds an HTTP transport with the settings
+func (t transportConfig) transport(insecure bool) (*http.Transport, error) {
+	transport := cleanhttp.DefaultTransport()
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+	}
+
+	if t.CAFile != "" || t.CAPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for _, source := range []struct{ name, file, pem string }{
+			{"ca_file", t.CAFile, ""},
+			{"ca_pem", "", t.CAPEM},
+		} {
+			data, err := readPEM(source.file, source.pem)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading %s: %s", source.name, err)
+			}
+			if data != nil && !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("No certificates found in %s", source.name)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	hasCert := t.ClientCertFile != "" || t.ClientCertPEM != ""
+	hasKey := t.ClientKeyFile != "" || t.ClientKeyPEM != ""
+	if hasCert != hasKey {
+		return nil, fmt.Errorf("Both a client certificate and a client key must be set")
+	}
+	if hasCert {
+		certPEM, err := readPEM(t.ClientCertFile, t.ClientCertPEM)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the client certificate: %s", err)
+		}
+		keyPEM, err := readPEM(t.ClientKeyFile, t.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the client key: %s", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading the client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.MinTLSVersion != "" {
+		version, ok := tlsVersions[t.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("Unknown min_tls_version %q", t.MinTLSVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if t.ProxyURL != "" {
+		proxyURL, err := url.Parse(t.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid proxy_url: %s", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if t.NoProxy != "" {
+		transport.Proxy = bypassProxy(t.NoProxy, transport.Proxy)
+	}
+
+	return transport, nil
+}
+
+// Wraps the proxy function so the hosts matching noProxy are reached directly, whether the proxy
+// comes from proxy_url or from the environment
+func bypassProxy(noProxy string, proxy func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if matchesNoProxy(noProxy, req.URL.Hostname()) {
+			return nil, nil
+		}
+		return proxy(req)
+	}
+}
+
+// Returns the content of the PEM file, or the PEM encoded value, or nil if neither is set
+func readPEM(file, value string) ([]byte, error) {
+	if value != "" {
+		return []byte(value), nil
+	}
+	if file == "" {
+		return nil, nil
+	}
+	path, err := homedir.Expand(file)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// Checks the host matches one of the comma separated entries, in the same format as the NO_PROXY
+// environment variable. An entry is either *, a host name which also matches its subdomains, a domain
+// starting with a dot, an IP address, or a CIDR block.
+func matchesNoProxy(noProxy, host string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		case ip != nil:
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+				return true
+			}
+			if ip.Equal(net.ParseIP(entry)) {
+				return true
+			}
+		case strings.HasPrefix(entry, "."):
+			if strings.HasSuffix(host, entry) || host == entry[1:] {
+				return true
+			}
+		case host == entry || strings.HasSuffix(host, "."+entry):
+			return true
+		}
+	}
+	return false
+}
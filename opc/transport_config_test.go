@@ -0,0 +1,214 @@
+package opc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransportConfig_caPEM(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The server's self-signed certificate isn't trusted by default
+	transport, err := transportConfig{}.transport(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&http.Client{Transport: transport}).Get(server.URL); err == nil {
+		t.Fatal("Expected an unknown certificate authority error")
+	}
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	transport, err = transportConfig{CAPEM: string(caPEM)}.transport(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the certificate authority to be trusted, got %s", err)
+	}
+	resp.Body.Close()
+
+	if _, err := (transportConfig{CAPEM: "not a certificate"}).transport(false); err == nil || !strings.Contains(err.Error(), "No certificates found in ca_pem") {
+		t.Fatalf("Expected a no certificates error, got %v", err)
+	}
+}
+
+func TestTransportConfig_clientCertificate(t *testing.T) {
+	certPEM, keyPEM := testGenerateCertificate(t)
+
+	transport, err := transportConfig{ClientCertPEM: certPEM, ClientKeyPEM: keyPEM}.transport(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.TLSClientConfig.Certificates) != 1 {
+		t.Fatalf("Expected a client certificate, got %d", len(transport.TLSClientConfig.Certificates))
+	}
+
+	if _, err := (transportConfig{ClientCertPEM: certPEM}).transport(false); err == nil || !strings.Contains(err.Error(), "Both a client certificate and a client key must be set") {
+		t.Fatalf("Expected a missing key error, got %v", err)
+	}
+	if _, err := (transportConfig{ClientCertPEM: certPEM, ClientKeyFile: "/does/not/exist"}).transport(false); err == nil || !strings.Contains(err.Error(), "Error reading the client key") {
+		t.Fatalf("Expected a key file error, got %v", err)
+	}
+}
+
+func TestTransportConfig_tlsAndProxy(t *testing.T) {
+	transport, err := transportConfig{
+		MinTLSVersion: "1.2",
+		ProxyURL:      "http://proxy.example.com:3128",
+		NoProxy:       "internal.example.com",
+	}.transport(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS12 || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Fatalf("Expected TLS 1.2 and insecure, got %#v", transport.TLSClientConfig)
+	}
+
+	for rawURL, expected := range map[string]string{
+		"https://compute.example.com/instance/":     "http://proxy.example.com:3128",
+		"https://api.internal.example.com/instance": "",
+	} {
+		u, _ := url.Parse(rawURL)
+		proxy, err := transport.Proxy(&http.Request{URL: u})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (proxy == nil && expected != "") || (proxy != nil && proxy.String() != expected) {
+			t.Errorf("Expected the proxy for %s to be %q, got %v", rawURL, expected, proxy)
+		}
+	}
+}
+
+func TestTransportConfig_merge(t *testing.T) {
+	provider := transportConfig{CAFile: "/provider/ca.pem", ProxyURL: "http://proxy:3128", MinTLSVersion: "1.2"}
+	merged := provider.merge(transportConfig{ProxyURL: "http://storage-proxy:3128"})
+	expected := transportConfig{CAFile: "/provider/ca.pem", ProxyURL: "http://storage-proxy:3128", MinTLSVersion: "1.2"}
+	if merged != expected {
+		t.Fatalf("Expected %#v, got %#v", expected, merged)
+	}
+}
+
+func TestMatchesNoProxy(t *testing.T) {
+	cases := []struct {
+		NoProxy string
+		Host    string
+		Match   bool
+	}{
+		{"", "example.com", false},
+		{"*", "example.com", true},
+		{"example.com", "example.com", true},
+		{"example.com", "api.example.com", true},
+		{"example.com", "notexample.com", false},
+		{".example.com", "api.example.com", true},
+		{".example.com", "example.com", true},
+		{"other.com, Example.com:443", "EXAMPLE.com", true},
+		{"10.0.0.0/8", "10.1.2.3", true},
+		{"10.0.0.0/8", "192.168.1.1", false},
+		{"192.168.1.1", "192.168.1.1", true},
+		{"::1", "::1", true},
+	}
+
+	for _, tc := range cases {
+		if match := matchesNoProxy(tc.NoProxy, tc.Host); match != tc.Match {
+			t.Errorf("Expected %q matching %q to be %t, got %t", tc.NoProxy, tc.Host, tc.Match, match)
+		}
+	}
+}
+
+func TestProviderConfig_transport(t *testing.T) {
+	defer testUnsetEnv("OPC_CA_FILE", "OPC_CLIENT_CERT_FILE", "OPC_CLIENT_KEY_FILE", "OPC_MIN_TLS_VERSION",
+		"OPC_PROXY_URL", "OPC_NO_PROXY")()
+
+	config := testProviderConfig(t, map[string]interface{}{
+		"user":            "user",
+		"password":        "password",
+		"identity_domain": "domain",
+		"proxy_url":       "http://proxy:3128",
+		"min_tls_version": "1.2",
+		"storage_transport": []interface{}{
+			map[string]interface{}{
+				"proxy_url": "http://storage-proxy:3128",
+				"no_proxy":  "internal",
+			},
+		},
+	})
+	if config.Transport.ProxyURL != "http://proxy:3128" || config.Transport.MinTLSVersion != "1.2" {
+		t.Fatalf("Expected the provider transport settings, got %#v", config.Transport)
+	}
+	if config.StorageTransport.ProxyURL != "http://storage-proxy:3128" || config.StorageTransport.NoProxy != "internal" {
+		t.Fatalf("Expected the storage transport settings, got %#v", config.StorageTransport)
+	}
+	if config.ComputeTransport != (transportConfig{}) {
+		t.Fatalf("Expected no compute transport settings, got %#v", config.ComputeTransport)
+	}
+}
+
+// Generates a self-signed certificate and its key, PEM encoded
+func testGenerateCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestBypassProxy(t *testing.T) {
+	envProxy, _ := url.Parse("http://env-proxy.example.com:3128")
+	proxy := bypassProxy("internal.example.com,10.0.0.0/8", http.ProxyURL(envProxy))
+
+	for rawURL, expected := range map[string]string{
+		"https://compute.example.com/instance/":     "http://env-proxy.example.com:3128",
+		"https://api.internal.example.com/instance": "",
+		"https://10.1.2.3/instance/":                "",
+	} {
+		u, _ := url.Parse(rawURL)
+		p, err := proxy(&http.Request{URL: u})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (p == nil && expected != "") || (p != nil && p.String() != expected) {
+			t.Errorf("Expected the proxy for %s to be %q, got %v", rawURL, expected, p)
+		}
+	}
+
+	// Without proxy_url, no_proxy still applies to the proxy from the environment
+	transport, err := transportConfig{NoProxy: "internal.example.com"}.transport(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse("https://api.internal.example.com/instance")
+	if p, err := transport.Proxy(&http.Request{URL: u}); err != nil || p != nil {
+		t.Fatalf("Expected no proxy for %s, got %v, %v", u, p, err)
+	}
+}
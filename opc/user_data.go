@@ -0,0 +1,203 @@
+package opc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Returns the schema of the user_data block, which is merged into the userdata instance attribute
+// read by cloud-init and opc-init when the instance boots
+func userDataSchema(forceNew bool) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		ForceNew: forceNew,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cloud_init": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ForceNew:     forceNew,
+					ValidateFunc: validateCloudInit,
+				},
+				"packages": {
+					Type:     schema.TypeList,
+					Optional: true,
+					ForceNew: forceNew,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"pre_bootstrap": {
+					Type:     schema.TypeList,
+					Optional: true,
+					ForceNew: forceNew,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"script": {
+								Type:     schema.TypeList,
+								Required: true,
+								ForceNew: forceNew,
+								MinItems: 1,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+							"fail_on_error": {
+								Type:     schema.TypeBool,
+								Optional: true,
+								ForceNew: forceNew,
+								Default:  false,
+							},
+						},
+					},
+				},
+				"chef": {
+					Type:     schema.TypeList,
+					Optional: true,
+					ForceNew: forceNew,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"server_url": {
+								Type:     schema.TypeString,
+								Required: true,
+								ForceNew: forceNew,
+							},
+							"validation_name": {
+								Type:     schema.TypeString,
+								Optional: true,
+								ForceNew: forceNew,
+							},
+							"validation_key": {
+								Type:      schema.TypeString,
+								Optional:  true,
+								ForceNew:  forceNew,
+								Sensitive: true,
+							},
+							"run_list": {
+								Type:     schema.TypeList,
+								Optional: true,
+								ForceNew: forceNew,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+							"node_name": {
+								Type:     schema.TypeString,
+								Optional: true,
+								ForceNew: forceNew,
+							},
+							"environment": {
+								Type:     schema.TypeString,
+								Optional: true,
+								ForceNew: forceNew,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Expands the user_data block to the fields of the userdata instance attribute
+func expandUserData(userData []interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	if len(userData) == 0 || userData[0] == nil {
+		return result
+	}
+	config := userData[0].(map[string]interface{})
+
+	if v := config["cloud_init"].(string); v != "" {
+		result["user_data"] = v
+	}
+	if v := config["packages"].([]interface{}); len(v) > 0 {
+		result["packages"] = v
+	}
+	if v := config["pre_bootstrap"].([]interface{}); len(v) > 0 && v[0] != nil {
+		preBootstrap := v[0].(map[string]interface{})
+		result["pre-bootstrap"] = map[string]interface{}{
+			"script":      preBootstrap["script"],
+			"failonerror": preBootstrap["fail_on_error"],
+		}
+	}
+	if v := config["chef"].([]interface{}); len(v) > 0 && v[0] != nil {
+		chef := v[0].(map[string]interface{})
+		attrs := map[string]interface{}{
+			"chef_server_url": chef["server_url"],
+		}
+		for key, attr := range map[string]string{
+			"validation_name": "chef_validation_name",
+			"validation_key":  "chef_validation_key",
+			"node_name":       "chef_node_name",
+			"environment":     "chef_environment",
+		} {
+			if value := chef[key].(string); value != "" {
+				attrs[attr] = value
+			}
+		}
+		if runList := chef["run_list"].([]interface{}); len(runList) > 0 {
+			attrs["chef_run_list"] = runList
+		}
+		result["chef"] = attrs
+	}
+	return result
+}
+
+// Merges the user_data block into the userdata of the instance attributes, returning an error if
+// instance_attributes already sets one of its fields
+func mergeUserData(attributes map[string]interface{}, userData []interface{}) (map[string]interface{}, error) {
+	fields := expandUserData(userData)
+	if len(fields) == 0 {
+		return attributes, nil
+	}
+	if attributes == nil {
+		attributes = make(map[string]interface{})
+	}
+
+	merged := make(map[string]interface{})
+	if existing, ok := attributes["userdata"]; ok && existing != nil {
+		existingFields, ok := existing.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("userdata in instance_attributes must be an object to be merged with user_data")
+		}
+		for key, value := range existingFields {
+			merged[key] = value
+		}
+	}
+	for key, value := range fields {
+		if _, ok := merged[key]; ok {
+			return nil, fmt.Errorf("userdata.%s is set in both instance_attributes and user_data", key)
+		}
+		merged[key] = value
+	}
+	attributes["userdata"] = merged
+	return attributes, nil
+}
+
+// Removes the fields merged from the user_data block from the userdata of the instance attributes
+func removeUserData(attributes map[string]interface{}, userData []interface{}) {
+	fields := expandUserData(userData)
+	existing, ok := attributes["userdata"].(map[string]interface{})
+	if !ok || len(fields) == 0 {
+		return
+	}
+	for key := range fields {
+		delete(existing, key)
+	}
+	if len(existing) == 0 {
+		delete(attributes, "userdata")
+	}
+}
+
+// Checks the user_data block can be merged into the instance_attributes JSON, so that mistakes are
+// reported by the plan rather than when the instance boots
+func checkUserData(attributes string, userData []interface{}) error {
+	var attrs map[string]interface{}
+	if attributes != "" {
+		if err := json.Unmarshal([]byte(attributes), &attrs); err != nil {
+			return fmt.Errorf("Cannot parse attributes as json: %s", err)
+		}
+	}
+	_, err := mergeUserData(attrs, userData)
+	return err
+}
@@ -0,0 +1,89 @@
+package opc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testUserData() []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"cloud_init": "#cloud-config\npackages:\n  - git\n",
+			"packages":   []interface{}{"httpd"},
+			"pre_bootstrap": []interface{}{
+				map[string]interface{}{
+					"script":        []interface{}{"touch /tmp/hello"},
+					"fail_on_error": true,
+				},
+			},
+			"chef": []interface{}{
+				map[string]interface{}{
+					"server_url":      "https://chef.example.com/organizations/test",
+					"validation_name": "test-validator",
+					"validation_key":  "",
+					"run_list":        []interface{}{"recipe[test]"},
+					"node_name":       "",
+					"environment":     "",
+				},
+			},
+		},
+	}
+}
+
+func TestMergeUserData(t *testing.T) {
+	attrs := map[string]interface{}{
+		"enable_admin": true,
+		"userdata": map[string]interface{}{
+			"opc-init": "1.0",
+		},
+	}
+
+	merged, err := mergeUserData(attrs, testUserData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(merged)
+	expected := `{"enable_admin":true,"userdata":{` +
+		`"chef":{"chef_run_list":["recipe[test]"],"chef_server_url":"https://chef.example.com/organizations/test","chef_validation_name":"test-validator"},` +
+		`"opc-init":"1.0",` +
+		`"packages":["httpd"],` +
+		`"pre-bootstrap":{"failonerror":true,"script":["touch /tmp/hello"]},` +
+		`"user_data":"#cloud-config\npackages:\n  - git\n"}}`
+	if string(b) != expected {
+		t.Fatalf("Expected %s, got %s", expected, b)
+	}
+
+	removeUserData(merged, testUserData())
+	b, _ = json.Marshal(merged)
+	if expected := `{"enable_admin":true,"userdata":{"opc-init":"1.0"}}`; string(b) != expected {
+		t.Fatalf("Expected %s once the user data is removed, got %s", expected, b)
+	}
+}
+
+func TestMergeUserData_empty(t *testing.T) {
+	merged, err := mergeUserData(nil, []interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != nil {
+		t.Fatalf("Expected no attributes, got %#v", merged)
+	}
+}
+
+func TestCheckUserData(t *testing.T) {
+	if err := checkUserData("", testUserData()); err != nil {
+		t.Fatalf("Expected the user data to be valid, got %s", err)
+	}
+
+	cases := map[string]string{
+		`{"userdata": {"pre-bootstrap": {"script": ["true"]}}}`: "userdata.pre-bootstrap is set in both instance_attributes and user_data",
+		`{"userdata": "#cloud-config"}`:                         "userdata in instance_attributes must be an object",
+		`{"userdata":`:                                          "Cannot parse attributes as json",
+	}
+	for attributes, expected := range cases {
+		if err := checkUserData(attributes, testUserData()); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q for %s, got %v", expected, attributes, err)
+		}
+	}
+}
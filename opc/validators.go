@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-oracle-terraform/compute"
 )
@@ -132,3 +134,43 @@ func validateOriginServerPoolURI(v interface{}, k string) (ws []string, errors [
 	}
 	return
 }
+
+// Check the value parses as a non-negative Go duration, e.g. "30s" or "5m"
+func validateDuration(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a valid duration such as \"30s\" or \"5m\", got error while parsing: %s", k, err))
+		return
+	}
+	if duration < 0 {
+		errors = append(errors, fmt.Errorf(
+			"%q cannot be negative. Got: %s", k, value))
+	}
+	return
+}
+
+// The API clients poll in whole seconds, so shorter intervals would never wait
+func validatePollInterval(v interface{}, k string) (ws []string, errors []error) {
+	if ws, errors = validateDuration(v, k); len(errors) > 0 {
+		return
+	}
+	if duration, _ := time.ParseDuration(v.(string)); duration < time.Second {
+		errors = append(errors, fmt.Errorf(
+			"%q must be at least 1s. Got: %s", k, v.(string)))
+	}
+	return
+}
+
+// cloud-init only runs user data that starts with a header saying what it holds
+func validateCloudInit(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+
+	if !strings.HasPrefix(value, "#cloud-config") && !strings.HasPrefix(value, "#!") {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a cloud-config document starting with #cloud-config, or a script starting with #!", k))
+	}
+	return
+}
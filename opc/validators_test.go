@@ -279,3 +279,65 @@ func TestValidateOriginServerPoolURI(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDuration(t *testing.T) {
+	validDurations := []string{
+		"0s",
+		"30s",
+		"5m",
+		"1h30m",
+	}
+
+	for _, v := range validDurations {
+		_, errors := validateDuration(v, "duration")
+		if len(errors) != 0 {
+			t.Fatalf("%q should be a valid duration: %q", v, errors)
+		}
+	}
+
+	invalidDurations := []string{
+		"",
+		"5",
+		"-1m",
+		"ten seconds",
+	}
+
+	for _, v := range invalidDurations {
+		_, errors := validateDuration(v, "duration")
+		if len(errors) == 0 {
+			t.Fatalf("%q should not be a valid duration", v)
+		}
+	}
+}
+
+func TestValidatePollInterval(t *testing.T) {
+	for _, v := range []string{"1s", "10s", "2m"} {
+		_, errors := validatePollInterval(v, "poll_interval")
+		if len(errors) != 0 {
+			t.Fatalf("%q should be a valid poll interval: %q", v, errors)
+		}
+	}
+
+	for _, v := range []string{"0s", "500ms", "-1s", "soon"} {
+		_, errors := validatePollInterval(v, "poll_interval")
+		if len(errors) == 0 {
+			t.Fatalf("%q should not be a valid poll interval", v)
+		}
+	}
+}
+
+func TestValidateCloudInit(t *testing.T) {
+	for _, v := range []string{"#cloud-config\npackages:\n  - git\n", "#!/bin/sh\necho hello\n"} {
+		_, errors := validateCloudInit(v, "cloud_init")
+		if len(errors) != 0 {
+			t.Fatalf("%q should be valid cloud-init user data: %q", v, errors)
+		}
+	}
+
+	for _, v := range []string{"", "packages:\n  - git\n", " #cloud-config"} {
+		_, errors := validateCloudInit(v, "cloud_init")
+		if len(errors) == 0 {
+			t.Fatalf("%q should not be valid cloud-init user data", v)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package opc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// The first wait between checks when the provider configuration doesn't set poll_interval
+	defaultPollInterval = 1 * time.Second
+	// The longest wait between checks when the provider configuration doesn't set poll_max_interval
+	defaultPollMaxInterval = 30 * time.Second
+)
+
+// pollPolicy decides how often the provider checks the state of a resource while waiting for it
+type pollPolicy struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+}
+
+// Returns the wait before the given check, doubling from the interval up to the maximum interval
+func (p pollPolicy) wait(check int) time.Duration {
+	wait := p.Interval
+	for i := 1; i < check && wait < p.MaxInterval; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxInterval {
+		wait = p.MaxInterval
+	}
+	return wait
+}
+
+// Waits for the check to report it's done, returning its error if it fails. Waiting stops
+// as soon as Terraform is interrupted, or the timeout is reached.
+func (c *Client) waitFor(description string, timeout time.Duration, check func() (bool, error)) error {
+	ctx := c.stopContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+
+		wait := c.pollPolicy.wait(attempt)
+		log.Printf("[DEBUG] Waiting %s for %s", wait, description)
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("Timeout after %s waiting for %s", timeout, description)
+			}
+			return fmt.Errorf("Interrupted while waiting for %s", description)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Runs an API client call which waits for a resource itself, returning as soon as Terraform is
+// interrupted rather than after the API client's next poll interval. The API client's requests are
+// cancelled by the same context, so the abandoned call fails at its next check.
+func (c *Client) interruptible(description string, call func() error) error {
+	if c.stopContext == nil {
+		return call()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- call()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-c.stopContext.Done():
+		return fmt.Errorf("Interrupted while waiting for %s", description)
+	}
+}
@@ -0,0 +1,132 @@
+package opc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollPolicyWait(t *testing.T) {
+	policy := pollPolicy{Interval: time.Second, MaxInterval: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, wait := range expected {
+		if actual := policy.wait(i + 1); actual != wait {
+			t.Errorf("Expected check %d to wait %s, got %s", i+1, wait, actual)
+		}
+	}
+}
+
+func TestClientWaitFor(t *testing.T) {
+	client := &Client{pollPolicy: pollPolicy{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond}}
+
+	checks := 0
+	err := client.waitFor("test", time.Minute, func() (bool, error) {
+		checks++
+		return checks == 3, nil
+	})
+	if err != nil || checks != 3 {
+		t.Fatalf("Expected 3 checks and no error, got %d and %v", checks, err)
+	}
+
+	failed := errors.New("failed")
+	if err := client.waitFor("test", time.Minute, func() (bool, error) { return false, failed }); err != failed {
+		t.Fatalf("Expected the check's error, got %v", err)
+	}
+
+	err = client.waitFor("test", 20*time.Millisecond, func() (bool, error) { return false, nil })
+	if err == nil || !strings.Contains(err.Error(), "Timeout after 20ms waiting for test") {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+}
+
+func TestClientWaitFor_interrupted(t *testing.T) {
+	stop, cancel := context.WithCancel(context.Background())
+	client := &Client{stopContext: stop, pollPolicy: pollPolicy{Interval: time.Hour, MaxInterval: time.Hour}}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	err := client.waitFor("test", 2*time.Hour, func() (bool, error) { return false, nil })
+	if err == nil || !strings.Contains(err.Error(), "Interrupted while waiting for test") {
+		t.Fatalf("Expected an interruption, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the wait to stop when interrupted, took %s", elapsed)
+	}
+}
+
+func TestRetryTransport_interrupted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	stop, cancel := context.WithCancel(context.Background())
+	policy := testRetryPolicy()
+	policy.BaseBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	policy.MaxElapsedTime = 2 * time.Hour
+	transport := &retryTransport{policy: policy, transport: http.DefaultTransport, stop: stop}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := transport.RoundTrip(req); err != context.Canceled {
+		t.Fatalf("Expected the request to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the backoff to stop when interrupted, took %s", elapsed)
+	}
+}
+
+func TestClientInterruptible(t *testing.T) {
+	stop, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &Client{stopContext: stop}
+
+	failed := errors.New("failed")
+	if err := client.interruptible("test", func() error { return failed }); err != failed {
+		t.Fatalf("Expected the call's error, got %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	err := client.interruptible("test", func() error {
+		<-release
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "Interrupted while waiting for test") {
+		t.Fatalf("Expected an interruption, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the call to be abandoned when interrupted, took %s", elapsed)
+	}
+}
+
+func TestRetryTransport_uninterruptible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	stop, cancel := context.WithCancel(context.Background())
+	cancel()
+	transport := &uninterruptibleTransport{
+		transport: &retryTransport{policy: testRetryPolicy(), transport: http.DefaultTransport, stop: stop},
+	}
+
+	req, _ := http.NewRequest("POST", server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Expected the request to finish once Terraform is interrupted, got %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected HTTP 201, got %d", resp.StatusCode)
+	}
+}